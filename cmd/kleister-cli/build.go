@@ -1,12 +1,11 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/kleister/kleister-go/kleister"
@@ -43,6 +42,34 @@ Name: {{ .Version.Name }}
 Mod: {{ with .Version.Mod }}{{ . }}{{ else }}n/a{{ end }}
 `
 
+// tmplBuildOutdated represents a row within outdated version listing.
+var tmplBuildOutdated = "Mod: \x1b[33m{{ .Mod }}\x1b[0m" + `
+Current: {{ .CurrentName }}
+Latest: {{ .LatestName }}
+`
+
+// tmplBuildDiff represents the differences between two builds.
+var tmplBuildDiff = "From: \x1b[33m{{ .From }}\x1b[0m" + `
+To: ` + "\x1b[33m{{ .To }}\x1b[0m" + `{{ range .Fields }}
+{{ .Field }}: {{ .From | default "none" }} -> {{ .To | default "none" }}{{ end }}{{ range .Added }}
+` + "\x1b[32m+ {{ .Mod }} {{ .To }}\x1b[0m" + `{{ end }}{{ range .Removed }}
+` + "\x1b[31m- {{ .Mod }} {{ .From }}\x1b[0m" + `{{ end }}{{ range .Changed }}
+~ {{ .Mod }} {{ .From }} -> {{ .To }}{{ end }}{{ if .Empty }}
+No differences{{ end }}
+`
+
+// tmplBuildCheck represents a row within build problem listing.
+var tmplBuildCheck = "Mod: \x1b[33m{{ .Mod }}@{{ .Version }}\x1b[0m" + `
+Problem: {{ .Kind }}
+Message: {{ .Message }}
+`
+
+// tmplBuildValidate represents a row within build issue listing.
+var tmplBuildValidate = "Build: \x1b[33m{{ .Build }}\x1b[0m" + `
+Field: {{ .Field }}
+Message: {{ .Message }}
+`
+
 // Build provides the sub-command for the build API.
 func Build() *cli.Command {
 	return &cli.Command{
@@ -66,9 +93,29 @@ func Build() *cli.Command {
 						Usage: "custom output format",
 					},
 					&cli.StringFlag{
-						Name:  "output",
-						Value: "text",
-						Usage: "output as format, json or xml",
+						Name:  "columns",
+						Value: "",
+						Usage: "comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "sort by a field, prefix with a dash for descending order",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Value: "",
+						Usage: "filter by expression on the record fields",
+					},
+					&cli.BoolFlag{
+						Name:  "first",
+						Value: false,
+						Usage: "return only first record",
+					},
+					&cli.BoolFlag{
+						Name:  "last",
+						Value: false,
+						Usage: "return only last record",
 					},
 				},
 				Action: func(c *cli.Context) error {
@@ -95,11 +142,6 @@ func Build() *cli.Command {
 						Value: tmplBuildShow,
 						Usage: "custom output format",
 					},
-					&cli.StringFlag{
-						Name:  "output",
-						Value: "text",
-						Usage: "output as format, json or xml",
-					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, BuildShow)
@@ -191,6 +233,11 @@ func Build() *cli.Command {
 						Value: false,
 						Usage: "mark pack public",
 					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Value: false,
+						Usage: "skip the check of forge and minecraft compatibility",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, BuildUpdate)
@@ -256,11 +303,288 @@ func Build() *cli.Command {
 						Value: false,
 						Usage: "mark pack public",
 					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Value: false,
+						Usage: "skip the check of forge and minecraft compatibility",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, BuildCreate)
 				},
 			},
+			{
+				Name:      "copy",
+				Aliases:   []string{"cp"},
+				Usage:     "create a new build from an existing one",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "build id or slug to copy",
+					},
+					&cli.StringFlag{
+						Name:  "to-slug",
+						Value: "",
+						Usage: "slug for the new build",
+					},
+					&cli.StringFlag{
+						Name:  "to-name",
+						Value: "",
+						Usage: "name for the new build, defaults to the slug",
+					},
+					&cli.StringFlag{
+						Name:  "to-pack",
+						Value: "",
+						Usage: "id or slug of the target pack, defaults to the same pack",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildCopy)
+				},
+			},
+			{
+				Name:      "outdated",
+				Usage:     "list mods with newer versions",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "build id or slug to check",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: tmplBuildOutdated,
+						Usage: "custom output format",
+					},
+					&cli.BoolFlag{
+						Name:  "apply",
+						Value: false,
+						Usage: "replace the outdated versions within the build",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildOutdated)
+				},
+			},
+			{
+				Name:      "diff",
+				Usage:     "compare two builds or a build with a manifest",
+				ArgsUsage: "<from> <to>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.BoolFlag{
+						Name:  "manifest",
+						Value: false,
+						Usage: "always load the builds from manifest files",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: tmplBuildDiff,
+						Usage: "custom output format",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildDiff)
+				},
+			},
+			{
+				Name:      "changelog",
+				Usage:     "render a changelog between two builds",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Value: "",
+						Usage: "previous build id, slug or manifest",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Value: "",
+						Usage: "current build id, slug or manifest",
+					},
+					&cli.BoolFlag{
+						Name:  "manifest",
+						Value: false,
+						Usage: "always load the builds from manifest files",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "markdown",
+						Usage: "markdown, html, bbcode or a custom output format",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildChangelog)
+				},
+			},
+			{
+				Name:      "package",
+				Usage:     "download the version files into a mod repository",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "build id or slug to package",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Value: "",
+						Usage: "output directory for the repository",
+					},
+					&cli.StringFlag{
+						Name:  "side",
+						Value: "",
+						Usage: "package only the mods for client or server",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildPackage)
+				},
+			},
+			{
+				Name:      "export",
+				Usage:     "export the build as launcher instance",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "build id or slug to export",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "multimc",
+						Usage: "export format, can be multimc or curseforge",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Value: "",
+						Usage: "path for the archive, defaults to <pack>-<build>.zip",
+					},
+					&cli.StringFlag{
+						Name:  "mapping",
+						Value: "",
+						Usage: "file mapping mods to CurseForge project and file ids",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildExport)
+				},
+			},
+			{
+				Name:      "server-bundle",
+				Usage:     "write a ready to run server directory",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "build id or slug to bundle",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Value: "",
+						Usage: "output directory for the server",
+					},
+					&cli.BoolFlag{
+						Name:  "accept-eula",
+						Value: false,
+						Usage: "accept the minecraft eula within eula.txt",
+					},
+					&cli.StringFlag{
+						Name:    "installer-cache",
+						Value:   InstallerCachePath(),
+						Usage:   "directory containing the forge installer jars",
+						EnvVars: []string{"KLEISTER_INSTALLER_CACHE"},
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildServerBundle)
+				},
+			},
+			{
+				Name:      "check",
+				Usage:     "check the dependencies of all mods",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "build id or slug to check",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: tmplBuildCheck,
+						Usage: "custom output format",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildCheck)
+				},
+			},
+			{
+				Name:      "validate",
+				Usage:     "validate the loader and java settings of all builds",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "pack, p",
+						Value: "",
+						Usage: "id or slug of the related pack",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: tmplBuildValidate,
+						Usage: "custom output format",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, BuildValidate)
+				},
+			},
 			{
 				Name:  "version",
 				Usage: "version assignments",
@@ -287,9 +611,29 @@ func Build() *cli.Command {
 								Usage: "custom output format",
 							},
 							&cli.StringFlag{
-								Name:  "output",
-								Value: "text",
-								Usage: "output as format, json or xml",
+								Name:  "columns",
+								Value: "",
+								Usage: "comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "filter by expression on the record fields",
+							},
+							&cli.BoolFlag{
+								Name:  "first",
+								Value: false,
+								Usage: "return only first record",
+							},
+							&cli.BoolFlag{
+								Name:  "last",
+								Value: false,
+								Usage: "return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -373,107 +717,22 @@ func BuildList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	switch c.String("output") {
-	case "json":
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "xml":
-		res, err := xml.MarshalIndent(records, "", "  ")
+	return OutputList(c, records)
+}
 
-		if err != nil {
-			return err
-		}
+// BuildShow provides the sub-command to show build details.
+func BuildShow(c *cli.Context, client kleister.ClientAPI) error {
+	record, err := client.BuildGet(
+		GetPackParam(c),
+		GetIdentifierParam(c),
+	)
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "text":
-		if len(records) == 0 {
-			fmt.Fprintf(os.Stderr, "empty result\n")
-			return nil
-		}
+	if err != nil {
+		return err
+	}
 
-		tmpl, err := template.New(
-			"_",
-		).Funcs(
-			globalFuncMap,
-		).Funcs(
-			sprigFuncMap,
-		).Parse(
-			fmt.Sprintf("%s\n", c.String("format")),
-		)
-
-		if err != nil {
-			return err
-		}
-
-		for _, record := range records {
-			err := tmpl.Execute(os.Stdout, record)
-
-			if err != nil {
-				return err
-			}
-		}
-	default:
-		return fmt.Errorf("invalid output type")
-	}
-
-	return nil
-}
-
-// BuildShow provides the sub-command to show build details.
-func BuildShow(c *cli.Context, client kleister.ClientAPI) error {
-	record, err := client.BuildGet(
-		GetPackParam(c),
-		GetIdentifierParam(c),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	switch c.String("output") {
-	case "json":
-		res, err := json.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "xml":
-		res, err := xml.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "text":
-		tmpl, err := template.New(
-			"_",
-		).Funcs(
-			globalFuncMap,
-		).Funcs(
-			sprigFuncMap,
-		).Parse(
-			fmt.Sprintf("%s\n", c.String("format")),
-		)
-
-		if err != nil {
-			return err
-		}
-
-		return tmpl.Execute(os.Stdout, record)
-	default:
-		return fmt.Errorf("invalid output type")
-	}
-
-	return nil
-}
+	return OutputRecord(c, record)
+}
 
 // BuildDelete provides the sub-command to delete a build.
 func BuildDelete(c *cli.Context, client kleister.ClientAPI) error {
@@ -599,6 +858,12 @@ func BuildUpdate(c *cli.Context, client kleister.ClientAPI) error {
 		changed = true
 	}
 
+	if changed && (c.IsSet("minecraft") || c.IsSet("forge")) {
+		if err := checkBuildLoader(client, record, c.Bool("force")); err != nil {
+			return err
+		}
+	}
+
 	if changed {
 		_, patch := client.BuildPatch(
 			GetPackParam(c),
@@ -733,6 +998,12 @@ func BuildCreate(c *cli.Context, client kleister.ClientAPI) error {
 		record.Private = false
 	}
 
+	if c.IsSet("minecraft") || c.IsSet("forge") {
+		if err := checkBuildLoader(client, record, c.Bool("force")); err != nil {
+			return err
+		}
+	}
+
 	_, err := client.BuildPost(
 		GetPackParam(c),
 		record,
@@ -746,9 +1017,24 @@ func BuildCreate(c *cli.Context, client kleister.ClientAPI) error {
 	return nil
 }
 
-// BuildVersionList provides the sub-command to list versions of the build.
-func BuildVersionList(c *cli.Context, client kleister.ClientAPI) error {
-	records, err := client.BuildVersionList(
+// BuildCopy provides the sub-command to copy a build including the versions,
+// the new build gets published after all versions have been appended if the
+// source build is published.
+func BuildCopy(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("to-slug") == "" {
+		return fmt.Errorf("you must provide a slug for the new build")
+	}
+
+	source, err := client.BuildGet(
+		GetPackParam(c),
+		GetIdentifierParam(c),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	versions, err := client.BuildVersionList(
 		kleister.BuildVersionParams{
 			Pack:  GetPackParam(c),
 			Build: GetIdentifierParam(c),
@@ -759,57 +1045,453 @@ func BuildVersionList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	switch c.String("output") {
-	case "json":
-		res, err := json.MarshalIndent(records, "", "  ")
+	target := GetPackParam(c)
 
-		if err != nil {
-			return err
+	if c.String("to-pack") != "" {
+		target = c.String("to-pack")
+	}
+
+	pack, err := client.PackGet(
+		target,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	record := &kleister.Build{
+		PackID:      pack.ID,
+		Slug:        c.String("to-slug"),
+		Name:        c.String("to-slug"),
+		MinecraftID: source.MinecraftID,
+		ForgeID:     source.ForgeID,
+		MinJava:     source.MinJava,
+		MinMemory:   source.MinMemory,
+		Private:     source.Private,
+	}
+
+	if val := c.String("to-name"); val != "" {
+		record.Name = val
+	}
+
+	resolver := NewModResolver(client)
+	pins := make([]kleister.BuildVersionParams, 0, len(versions))
+
+	for _, row := range versions {
+		if row.Version == nil {
+			continue
 		}
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "xml":
-		res, err := xml.MarshalIndent(records, "", "  ")
+		mod, err := resolver.Mod(row.Version)
 
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "text":
-		if len(records) == 0 {
-			fmt.Fprintf(os.Stderr, "empty result\n")
-			return nil
+		pins = append(pins, kleister.BuildVersionParams{
+			Pack:    pack.Slug,
+			Build:   record.Slug,
+			Mod:     mod.Slug,
+			Version: row.Version.Slug,
+		})
+	}
+
+	build, err := client.BuildPost(
+		pack.Slug,
+		record,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for _, pin := range pins {
+		pin.Build = build.Slug
+
+		if err := client.BuildVersionAppend(pin); err != nil {
+			if remove := client.BuildDelete(pack.Slug, build.Slug); remove != nil {
+				return fmt.Errorf("failed to append %s@%s and to remove the incomplete build %s. %s", pin.Mod, pin.Version, build.Slug, err)
+			}
+
+			return fmt.Errorf("failed to append %s@%s, removed the incomplete build. %s", pin.Mod, pin.Version, err)
+		}
+	}
+
+	if source.Published {
+		build.Published = true
+
+		if _, err := client.BuildPatch(pack.Slug, build); err != nil {
+			return fmt.Errorf("failed to publish build %s. %s", build.Slug, err)
 		}
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully copied\n")
+	return nil
+}
 
-		tmpl, err := template.New(
-			"_",
-		).Funcs(
-			globalFuncMap,
-		).Funcs(
-			sprigFuncMap,
-		).Parse(
-			fmt.Sprintf("%s\n", c.String("format")),
+// BuildOutdated provides the sub-command to list or update outdated versions.
+func BuildOutdated(c *cli.Context, client kleister.ClientAPI) error {
+	records, err := FindOutdated(
+		client,
+		GetPackParam(c),
+		GetIdentifierParam(c),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool("apply") {
+		return OutputList(c, records)
+	}
+
+	for _, record := range records {
+		err := client.BuildVersionAppend(
+			kleister.BuildVersionParams{
+				Pack:    GetPackParam(c),
+				Build:   GetIdentifierParam(c),
+				Mod:     record.Mod,
+				Version: record.Latest,
+			},
 		)
 
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to append %s@%s. %s", record.Mod, record.Latest, err)
 		}
 
-		for _, record := range records {
-			err := tmpl.Execute(os.Stdout, record)
+		err = client.BuildVersionDelete(
+			kleister.BuildVersionParams{
+				Pack:    GetPackParam(c),
+				Build:   GetIdentifierParam(c),
+				Mod:     record.Mod,
+				Version: record.Current,
+			},
+		)
 
-			if err != nil {
-				return err
-			}
+		if err != nil {
+			return fmt.Errorf("failed to remove %s@%s, the build contains both versions now. %s", record.Mod, record.Current, err)
 		}
+
+		fmt.Fprintf(os.Stderr, "updated %s from %s to %s\n", record.Mod, record.CurrentName, record.LatestName)
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintf(os.Stderr, "build is up to date\n")
+	}
+
+	return nil
+}
+
+// BuildDiff provides the sub-command to compare two builds.
+func BuildDiff(c *cli.Context, client kleister.ClientAPI) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("you must provide two builds or manifests to compare")
+	}
+
+	resolver := NewModResolver(client)
+
+	from, err := LoadBuildSide(
+		client,
+		resolver,
+		GetPackParam(c),
+		c.Args().Get(0),
+		c.Bool("manifest"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	to, err := LoadBuildSide(
+		client,
+		resolver,
+		GetPackParam(c),
+		c.Args().Get(1),
+		c.Bool("manifest"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return OutputRecord(c, DiffBuilds(from, to))
+}
+
+// BuildChangelog provides the sub-command to render a changelog.
+func BuildChangelog(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("from") == "" || c.String("to") == "" {
+		return fmt.Errorf("you must provide a from and to build")
+	}
+
+	pack, err := client.PackGet(
+		GetPackParam(c),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	resolver := NewModResolver(client)
+
+	from, err := LoadBuildSide(
+		client,
+		resolver,
+		pack.Slug,
+		c.String("from"),
+		c.Bool("manifest"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	to, err := LoadBuildSide(
+		client,
+		resolver,
+		pack.Slug,
+		c.String("to"),
+		c.Bool("manifest"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	record, err := NewChangelog(
+		client,
+		pack.Name,
+		DiffBuilds(from, to),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if outputFormat(c) != "text" {
+		return OutputRecord(c, record)
+	}
+
+	format := c.String("format")
+
+	if val, ok := changelogFormats[strings.ToLower(format)]; ok {
+		format = val
+	}
+
+	tmpl, err := template.New(
+		"_",
+	).Funcs(
+		globalFuncMap,
+	).Funcs(
+		sprigFuncMap,
+	).Parse(
+		format,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(os.Stdout, record)
+}
+
+// BuildPackage provides the sub-command to package the files of a build.
+func BuildPackage(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("out") == "" {
+		return fmt.Errorf("you must provide an output directory")
+	}
+
+	switch c.String("side") {
+	case "", "client", "server":
 	default:
-		return fmt.Errorf("invalid output type")
+		return fmt.Errorf("invalid side, can be client or server")
 	}
 
+	index, err := PackageBuild(
+		client,
+		GetPackParam(c),
+		GetIdentifierParam(c),
+		c.String("out"),
+		c.String("side"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully packaged %d mods to %s\n", len(index.Mods), c.String("out"))
+	return nil
+}
+
+// BuildExport provides the sub-command to export a build as launcher instance.
+func BuildExport(c *cli.Context, client kleister.ClientAPI) error {
+	if _, ok := exportFormats[strings.ToLower(c.String("format"))]; !ok {
+		return fmt.Errorf("invalid export format, can be %s", strings.Join(exportFormatNames(), ", "))
+	}
+
+	mapping, err := LoadCurseMapping(
+		c.String("mapping"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	source, err := LoadExportSource(
+		client,
+		GetPackParam(c),
+		GetIdentifierParam(c),
+		"",
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer source.Close()
+	source.Projects = mapping
+
+	out := c.String("out")
+
+	if out == "" {
+		out = fmt.Sprintf("%s-%s.zip", source.Pack.Slug, source.Build.Slug)
+	}
+
+	err = ExportBuildArchive(
+		source,
+		c.String("format"),
+		out,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully exported to %s\n", out)
 	return nil
 }
 
+// BuildServerBundle provides the sub-command to write a server directory.
+func BuildServerBundle(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("out") == "" {
+		return fmt.Errorf("you must provide an output directory")
+	}
+
+	source, err := LoadExportSource(
+		client,
+		GetPackParam(c),
+		GetIdentifierParam(c),
+		"server",
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer source.Close()
+
+	bundle := &ServerBundle{
+		Out:    c.String("out"),
+		Cache:  c.String("installer-cache"),
+		Accept: c.Bool("accept-eula"),
+	}
+
+	if err := bundle.Write(source); err != nil {
+		return err
+	}
+
+	if !bundle.Accepted() {
+		fmt.Fprintf(os.Stderr, "warning: the eula is not accepted, use --accept-eula or edit eula.txt\n")
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully bundled %d mods to %s\n", len(source.Files), bundle.Out)
+	return nil
+}
+
+// BuildCheck provides the sub-command to check the dependencies of a build.
+func BuildCheck(c *cli.Context, client kleister.ClientAPI) error {
+	records, err := CheckBuild(
+		client,
+		GetPackParam(c),
+		GetIdentifierParam(c),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 && outputFormat(c) == "text" {
+		fmt.Fprintf(os.Stderr, "no problems found\n")
+		return nil
+	}
+
+	if err := OutputList(c, records); err != nil {
+		return err
+	}
+
+	if len(records) > 0 {
+		return fmt.Errorf("found %d problems", len(records))
+	}
+
+	return nil
+}
+
+// BuildValidate provides the sub-command to validate all builds of a pack.
+func BuildValidate(c *cli.Context, client kleister.ClientAPI) error {
+	builds, err := client.BuildList(
+		GetPackParam(c),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	records := make([]*BuildIssue, 0)
+
+	for _, build := range builds {
+		issues, err := ValidateBuild(
+			client,
+			build,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		records = append(records, issues...)
+	}
+
+	if len(records) == 0 && outputFormat(c) == "text" {
+		fmt.Fprintf(os.Stderr, "all %d builds are valid\n", len(builds))
+		return nil
+	}
+
+	if err := OutputList(c, records); err != nil {
+		return err
+	}
+
+	if len(records) > 0 {
+		return fmt.Errorf("found %d issues", len(records))
+	}
+
+	return nil
+}
+
+// BuildVersionList provides the sub-command to list versions of the build.
+func BuildVersionList(c *cli.Context, client kleister.ClientAPI) error {
+	records, err := client.BuildVersionList(
+		kleister.BuildVersionParams{
+			Pack:  GetPackParam(c),
+			Build: GetIdentifierParam(c),
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return OutputList(c, records)
+}
+
 // BuildVersionAppend provides the sub-command to append a version to the build.
 func BuildVersionAppend(c *cli.Context, client kleister.ClientAPI) error {
 	err := client.BuildVersionAppend(
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+// tmplServerEULA represents the eula.txt of a server.
+var tmplServerEULA = `# By changing the setting below to true you are indicating your agreement to
+# the Minecraft EULA (https://account.mojang.com/documents/minecraft_eula).
+eula={{ .Accept }}
+`
+
+// tmplServerProperties represents the default server.properties of a server.
+var tmplServerProperties = `# Defaults for {{ .Pack }} {{ .Build }}, generated by kleister-cli.
+motd={{ .Pack }} {{ .Build }}
+server-port=25565
+max-players=20
+online-mode=true
+difficulty=normal
+gamemode=survival
+level-name=world
+view-distance=10
+spawn-protection=16
+enable-command-block=false
+allow-flight=true
+white-list=false
+`
+
+// tmplServerInstallShell represents the Forge installer script on Unix.
+var tmplServerInstallShell = `#!/bin/sh
+# Install script for {{ .Pack }} {{ .Build }}, generated by kleister-cli.
+cd "$(dirname "$0")" || exit 1
+
+JAVA="${JAVA:-java}"
+exec "$JAVA" -jar "{{ .Installer }}" --installServer
+`
+
+// tmplServerInstallBatch represents the Forge installer script on Windows.
+var tmplServerInstallBatch = `@echo off
+rem Install script for {{ .Pack }} {{ .Build }}, generated by kleister-cli.
+cd /d "%~dp0"
+
+if "%JAVA%"=="" set JAVA=java
+"%JAVA%" -jar "{{ .Installer }}" --installServer
+`
+
+// InstallerCachePath returns the default path of the Forge installer cache, it
+// respects the XDG_CACHE_HOME environment variable.
+func InstallerCachePath() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "kleister", "installers")
+	}
+
+	for _, env := range []string{"HOME", "USERPROFILE"} {
+		if home := os.Getenv(env); home != "" {
+			return filepath.Join(home, ".cache", "kleister", "installers")
+		}
+	}
+
+	return filepath.Join(".kleister", "installers")
+}
+
+// ServerBundle represents the options to write a server directory.
+type ServerBundle struct {
+	Out    string
+	Cache  string
+	Accept bool
+}
+
+// Write lays out a ready to run server for the build within the output
+// directory: the server side mods, eula.txt, server.properties, start scripts
+// and a script running the Forge installer taken from the cache. Existing
+// server.properties and an accepted EULA are kept.
+func (b *ServerBundle) Write(source *ExportSource) error {
+	launch, err := NewServerLaunch(
+		source.Pack,
+		source.Build,
+		source.Minecraft,
+		source.Forge,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	files, err := launch.Scripts()
+
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"Pack":   launch.Pack,
+		"Build":  launch.Build,
+		"Accept": b.Accept,
+	}
+
+	if source.Forge != nil {
+		installer, err := b.copyInstaller(source.Minecraft, source.Forge)
+
+		if err != nil {
+			return err
+		}
+
+		data["Installer"] = installer
+
+		for name, format := range map[string]string{
+			"install.sh":  tmplServerInstallShell,
+			"install.bat": tmplServerInstallBatch,
+		} {
+			content, err := renderServerTemplate(format, data)
+
+			if err != nil {
+				return err
+			}
+
+			if strings.HasSuffix(name, ".bat") {
+				content = strings.Replace(content, "\n", "\r\n", -1)
+			}
+
+			files[name] = content
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "warning: build has no forge, place %s within %s\n", launch.Jar, b.Out)
+	}
+
+	for name, format := range map[string]string{
+		"eula.txt":          tmplServerEULA,
+		"server.properties": tmplServerProperties,
+	} {
+		if b.keepFile(name) {
+			continue
+		}
+
+		content, err := renderServerTemplate(format, data)
+
+		if err != nil {
+			return err
+		}
+
+		files[name] = content
+	}
+
+	if err := b.writeMods(source.Files); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		mode := os.FileMode(0644)
+
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0755
+		}
+
+		if err := b.writeFile(name, []byte(files[name]), mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMods extracts the version files into a staging directory first, the
+// mods folder gets only replaced after all files have been extracted.
+func (b *ServerBundle) writeMods(files []*BuildFile) error {
+	if err := os.MkdirAll(b.Out, 0755); err != nil {
+		return fmt.Errorf("failed to create %s", b.Out)
+	}
+
+	dir, err := ioutil.TempDir(b.Out, ".mods")
+
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory")
+	}
+
+	defer os.RemoveAll(dir)
+
+	staging := &ServerBundle{
+		Out: dir,
+	}
+
+	for _, file := range files {
+		err := walkVersionFile(file, func(name string, content []byte) error {
+			return staging.writeFile(name, content, 0644)
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to extract %s@%s. %s", file.Mod.Slug, file.Version.Slug, err)
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(b.Out, "mods")); err != nil {
+		return fmt.Errorf("failed to clean mods folder")
+	}
+
+	return filepath.Walk(dir, func(current string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, current)
+
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(b.Out, rel)
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create %s", filepath.Dir(target))
+		}
+
+		if err := os.Rename(current, target); err != nil {
+			return fmt.Errorf("failed to write %s", target)
+		}
+
+		return nil
+	})
+}
+
+// keepFile checks if an existing file should be kept, that applies to the
+// server.properties and an already accepted EULA.
+func (b *ServerBundle) keepFile(name string) bool {
+	content, err := ioutil.ReadFile(filepath.Join(b.Out, name))
+
+	if err != nil {
+		return false
+	}
+
+	if name == "eula.txt" {
+		return !b.Accept && strings.Contains(string(content), "eula=true")
+	}
+
+	return true
+}
+
+// Accepted checks if the eula.txt within the output directory accepts the
+// Minecraft EULA.
+func (b *ServerBundle) Accepted() bool {
+	return b.keepFile("eula.txt") || b.Accept
+}
+
+// copyInstaller copies the Forge installer from the cache into the output
+// directory and returns its filename.
+func (b *ServerBundle) copyInstaller(minecraft *kleister.Minecraft, forge *kleister.Forge) (string, error) {
+	version := ForgeVersion(forge)
+
+	if minecraft != nil {
+		version = fmt.Sprintf("%s-%s", minecraft.Version, version)
+	}
+
+	name := fmt.Sprintf("forge-%s-installer.jar", version)
+	content, err := ioutil.ReadFile(filepath.Join(b.Cache, name))
+
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed to find %s within %s, download it from https://maven.minecraftforge.net/net/minecraftforge/forge/%s/%s",
+			name,
+			b.Cache,
+			version,
+			name,
+		)
+	}
+
+	if err := b.writeFile(name, content, 0644); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// writeFile writes a file relative to the output directory, it refuses paths
+// pointing outside of it.
+func (b *ServerBundle) writeFile(name string, content []byte, mode os.FileMode) error {
+	target := filepath.Join(b.Out, filepath.FromSlash(name))
+
+	if rel, err := filepath.Rel(b.Out, target); err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("invalid path %s", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s", filepath.Dir(target))
+	}
+
+	if err := ioutil.WriteFile(target, content, mode); err != nil {
+		return fmt.Errorf("failed to write %s", target)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func testBundleSource(t *testing.T, files ...string) *ExportSource {
+	result := &ExportSource{
+		Pack: &kleister.Pack{
+			Name: "Demo\nmotd=injected",
+		},
+		Build: &kleister.Build{
+			Name: "1.0.0",
+		},
+		Minecraft: &kleister.Minecraft{
+			Version: "1.12.2",
+		},
+	}
+
+	for _, local := range files {
+		result.Files = append(result.Files, &BuildFile{
+			Mod:     &kleister.Mod{Slug: "ironchest"},
+			Version: &kleister.Version{Slug: filepath.Base(local)},
+			Local:   local,
+		})
+	}
+
+	return result
+}
+
+func TestServerBundleWrite(t *testing.T) {
+	out := t.TempDir()
+	previous := filepath.Join(out, "mods", "previous.jar")
+
+	if err := os.MkdirAll(filepath.Dir(previous), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(previous, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jar := testJar(t, map[string][]byte{
+		"mcmod.info": []byte(testMcmodInfo),
+	})
+
+	bundle := &ServerBundle{
+		Out: out,
+	}
+
+	if err := bundle.Write(testBundleSource(t, jar, filepath.Join(out, "missing.jar"))); err == nil {
+		t.Fatal("expected an error for a missing version file")
+	}
+
+	if _, err := os.Stat(previous); err != nil {
+		t.Errorf("expected the previous mods to be kept, %s", err)
+	}
+
+	if err := bundle.Write(testBundleSource(t, jar)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(previous); !os.IsNotExist(err) {
+		t.Errorf("expected the previous mods to be removed")
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "mods", "ironchest-mod.jar.jar")); err != nil {
+		t.Errorf("expected the extracted mod, %s", err)
+	}
+
+	entries, err := ioutil.ReadDir(out)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".mods") {
+			t.Errorf("expected the staging directory %s to be removed", entry.Name())
+		}
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(out, "server.properties"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(content), "\nmotd=injected") {
+		t.Errorf("name broke out of the motd, got %q", content)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+// tmplChangelogMarkdown represents a changelog formatted as markdown.
+var tmplChangelogMarkdown = `## {{ .Pack }} {{ .To }}
+{{ with .Loader }}
+### Loader
+{{ range . }}
+* {{ .Field }}: {{ .From | default "none" }} → {{ .To | default "none" }}
+{{- end }}
+{{ end }}{{ with .Added }}
+### Added
+{{ range . }}
+* {{ if .Website }}[{{ .Name }}]({{ .Website }}){{ else }}{{ .Name }}{{ end }} {{ .To }}{{ with .Author }} by {{ . }}{{ end }}
+{{- end }}
+{{ end }}{{ with .Updated }}
+### Updated
+{{ range . }}
+* {{ if .Website }}[{{ .Name }}]({{ .Website }}){{ else }}{{ .Name }}{{ end }} {{ .From }} → {{ .To }}
+{{- end }}
+{{ end }}{{ with .Removed }}
+### Removed
+{{ range . }}
+* {{ .Name }} {{ .From }}
+{{- end }}
+{{ end }}`
+
+// tmplChangelogHTML represents a changelog formatted as HTML.
+var tmplChangelogHTML = `<h2>{{ .Pack | html }} {{ .To | html }}</h2>
+{{- with .Loader }}
+<h3>Loader</h3>
+<ul>
+{{- range . }}
+  <li>{{ .Field }}: {{ .From | default "none" | html }} &rarr; {{ .To | default "none" | html }}</li>
+{{- end }}
+</ul>
+{{- end }}{{ with .Added }}
+<h3>Added</h3>
+<ul>
+{{- range . }}
+  <li>{{ if .Website }}<a href="{{ .Website | html }}">{{ .Name | html }}</a>{{ else }}{{ .Name | html }}{{ end }} {{ .To | html }}{{ with .Author }} by {{ . | html }}{{ end }}</li>
+{{- end }}
+</ul>
+{{- end }}{{ with .Updated }}
+<h3>Updated</h3>
+<ul>
+{{- range . }}
+  <li>{{ if .Website }}<a href="{{ .Website | html }}">{{ .Name | html }}</a>{{ else }}{{ .Name | html }}{{ end }} {{ .From | html }} &rarr; {{ .To | html }}</li>
+{{- end }}
+</ul>
+{{- end }}{{ with .Removed }}
+<h3>Removed</h3>
+<ul>
+{{- range . }}
+  <li>{{ .Name | html }} {{ .From | html }}</li>
+{{- end }}
+</ul>
+{{- end }}
+`
+
+// tmplChangelogBBCode represents a changelog formatted as BBCode.
+var tmplChangelogBBCode = `[size=5][b]{{ .Pack }} {{ .To }}[/b][/size]
+{{ with .Loader }}
+[b]Loader[/b]
+[list]
+{{- range . }}
+[*]{{ .Field }}: {{ .From | default "none" }} → {{ .To | default "none" }}
+{{- end }}
+[/list]
+{{ end }}{{ with .Added }}
+[b]Added[/b]
+[list]
+{{- range . }}
+[*]{{ if .Website }}[url={{ .Website }}]{{ .Name }}[/url]{{ else }}{{ .Name }}{{ end }} {{ .To }}{{ with .Author }} by {{ . }}{{ end }}
+{{- end }}
+[/list]
+{{ end }}{{ with .Updated }}
+[b]Updated[/b]
+[list]
+{{- range . }}
+[*]{{ if .Website }}[url={{ .Website }}]{{ .Name }}[/url]{{ else }}{{ .Name }}{{ end }} {{ .From }} → {{ .To }}
+{{- end }}
+[/list]
+{{ end }}{{ with .Removed }}
+[b]Removed[/b]
+[list]
+{{- range . }}
+[*]{{ .Name }} {{ .From }}
+{{- end }}
+[/list]
+{{ end }}`
+
+// changelogFormats maps the predefined changelog formats to templates.
+var changelogFormats = map[string]string{
+	"markdown": tmplChangelogMarkdown,
+	"html":     tmplChangelogHTML,
+	"bbcode":   tmplChangelogBBCode,
+}
+
+// Changelog represents the player facing changes between two builds.
+type Changelog struct {
+	Pack    string            `json:"pack" xml:"pack"`
+	From    string            `json:"from" xml:"from"`
+	To      string            `json:"to" xml:"to"`
+	Loader  []*FieldChange    `json:"loader" xml:"loader>field"`
+	Added   []*ChangelogEntry `json:"added" xml:"added>mod"`
+	Updated []*ChangelogEntry `json:"updated" xml:"updated>mod"`
+	Removed []*ChangelogEntry `json:"removed" xml:"removed>mod"`
+}
+
+// ChangelogEntry represents a changed mod within a changelog.
+type ChangelogEntry struct {
+	Slug    string `json:"slug" xml:"slug"`
+	Name    string `json:"name" xml:"name"`
+	Author  string `json:"author,omitempty" xml:"author,omitempty"`
+	Website string `json:"website,omitempty" xml:"website,omitempty"`
+	From    string `json:"from,omitempty" xml:"from,omitempty"`
+	To      string `json:"to,omitempty" xml:"to,omitempty"`
+}
+
+// NewChangelog enriches the differences of two builds with the details of
+// the related mods and the names of the versions. Changes of the Minecraft and
+// Forge versions are listed as loader changes.
+func NewChangelog(client kleister.ClientAPI, pack string, delta *BuildDelta) (*Changelog, error) {
+	result := &Changelog{
+		Pack:    pack,
+		From:    delta.From,
+		To:      delta.To,
+		Loader:  make([]*FieldChange, 0),
+		Added:   make([]*ChangelogEntry, 0),
+		Updated: make([]*ChangelogEntry, 0),
+		Removed: make([]*ChangelogEntry, 0),
+	}
+
+	for _, field := range delta.Fields {
+		if field.Field == "Minecraft" || field.Field == "Forge" {
+			result.Loader = append(result.Loader, field)
+		}
+	}
+
+	mods := make(map[string]*kleister.Mod)
+	names := make(map[string]map[string]string)
+
+	for _, group := range []struct {
+		changes []*VersionChange
+		target  *[]*ChangelogEntry
+	}{
+		{delta.Added, &result.Added},
+		{delta.Changed, &result.Updated},
+		{delta.Removed, &result.Removed},
+	} {
+		for _, change := range group.changes {
+			mod, ok := mods[change.Mod]
+
+			if !ok {
+				record, err := client.ModGet(
+					change.Mod,
+				)
+
+				if err != nil {
+					return nil, err
+				}
+
+				mod = record
+				mods[change.Mod] = mod
+
+				versions, err := client.VersionList(
+					change.Mod,
+				)
+
+				if err != nil {
+					return nil, err
+				}
+
+				names[change.Mod] = make(map[string]string, len(versions))
+
+				for _, version := range versions {
+					names[change.Mod][version.Slug] = versionName(version)
+				}
+			}
+
+			entry := &ChangelogEntry{
+				Slug:    mod.Slug,
+				Name:    mod.Name,
+				Author:  mod.Author,
+				Website: safeWebsite(mod.Website),
+				From:    changelogVersion(names[change.Mod], change.From),
+				To:      changelogVersion(names[change.Mod], change.To),
+			}
+
+			if entry.Name == "" {
+				entry.Name = mod.Slug
+			}
+
+			*group.target = append(*group.target, entry)
+		}
+	}
+
+	return result, nil
+}
+
+// changelogVersion returns the name of a version slug, it falls back to the
+// slug for unknown versions.
+func changelogVersion(names map[string]string, slug string) string {
+	if name, ok := names[slug]; ok && name != "" {
+		return name
+	}
+
+	return slug
+}
+
+// safeWebsite returns the website if it's an absolute http or https URL, any
+// other value gets dropped as it ends up within links of the changelog.
+func safeWebsite(value string) string {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return parsed.String()
+	}
+
+	return ""
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSafeWebsite(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"https://example.com/jei", "https://example.com/jei"},
+		{"HTTP://example.com", "http://example.com"},
+		{" https://example.com ", "https://example.com"},
+		{"javascript:alert(1)", ""},
+		{"JavaScript://example.com/%0aalert(1)", ""},
+		{"data:text/html;base64,PHNjcmlwdD4=", ""},
+		{"//example.com", ""},
+		{"example.com", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := safeWebsite(tt.value); got != tt.want {
+			t.Errorf("safeWebsite(%q): expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestChangelogVersion(t *testing.T) {
+	names := map[string]string{
+		"4-16-0": "4.16.0",
+		"hd-u":   "",
+	}
+
+	tests := []struct {
+		slug string
+		want string
+	}{
+		{"4-16-0", "4.16.0"},
+		{"hd-u", "hd-u"},
+		{"unknown", "unknown"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := changelogVersion(names, tt.slug); got != tt.want {
+			t.Errorf("changelogVersion(%q): expected %q, got %q", tt.slug, tt.want, got)
+		}
+	}
+}
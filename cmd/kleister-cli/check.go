@@ -0,0 +1,347 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+var (
+	// mavenRestrictions matches the restrictions of a Maven version range.
+	mavenRestrictions = regexp.MustCompile(`[\[(][^\])]*[\])]`)
+
+	// semverOperators matches the operator prefix of a Fabric version predicate.
+	semverOperators = regexp.MustCompile(`^(>=|<=|>|<|=|\^|~)?\s*(.*)$`)
+)
+
+// ignoredDependencies lists mod ids provided by the launcher or the runtime
+// which can't be checked against a build.
+var ignoredDependencies = map[string]bool{
+	"java":          true,
+	"javafml":       true,
+	"fml":           true,
+	"mcp":           true,
+	"fabricloader":  true,
+	"fabric-loader": true,
+}
+
+// BuildProblem represents a dependency problem within a build.
+type BuildProblem struct {
+	Mod        string `json:"mod" xml:"mod"`
+	Version    string `json:"version" xml:"version"`
+	Kind       string `json:"kind" xml:"kind"`
+	Dependency string `json:"dependency" xml:"dependency"`
+	Range      string `json:"range,omitempty" xml:"range,omitempty"`
+	Found      string `json:"found,omitempty" xml:"found,omitempty"`
+	Message    string `json:"message" xml:"message"`
+}
+
+// providedMod represents a mod id available within a build.
+type providedMod struct {
+	version string
+	owner   string
+}
+
+// CheckBuild reads the metadata of all version files within a build and
+// reports missing dependencies, version range violations and conflicts.
+func CheckBuild(client kleister.ClientAPI, pack, id string) ([]*BuildProblem, error) {
+	source, err := LoadExportSource(
+		client,
+		pack,
+		id,
+		"",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer source.Close()
+
+	return CheckSource(source), nil
+}
+
+// CheckSource reports the dependency problems of the downloaded version files,
+// mod ids are compared case insensitive as legacy mods often use Forge.
+func CheckSource(source *ExportSource) []*BuildProblem {
+	provided := make(map[string]*providedMod)
+
+	if source.Minecraft != nil {
+		provided["minecraft"] = &providedMod{
+			version: source.Minecraft.Version,
+			owner:   "minecraft",
+		}
+	}
+
+	if source.Forge != nil {
+		provided["forge"] = &providedMod{
+			version: ForgeVersion(source.Forge),
+			owner:   "forge",
+		}
+	}
+
+	metadata := make(map[*BuildFile][]*JarMetadata, len(source.Files))
+
+	for _, file := range source.Files {
+		records, err := ReadModMetadata(file.Local)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s@%s, %s\n", file.Mod.Slug, file.Version.Slug, err)
+			continue
+		}
+
+		metadata[file] = records
+
+		for _, record := range records {
+			for _, modid := range append([]string{record.ID}, record.Provides...) {
+				provided[strings.ToLower(modid)] = &providedMod{
+					version: record.Version,
+					owner:   fmt.Sprintf("%s@%s", file.Mod.Slug, file.Version.Slug),
+				}
+			}
+		}
+	}
+
+	result := make([]*BuildProblem, 0)
+
+	for _, file := range source.Files {
+		for _, record := range metadata[file] {
+			for _, dependency := range record.Dependencies {
+				modid := strings.ToLower(dependency.ModID)
+
+				if modid == strings.ToLower(record.ID) || ignoredDependencies[modid] {
+					continue
+				}
+
+				problem := checkDependency(
+					dependency,
+					provided[modid],
+				)
+
+				if problem == nil {
+					continue
+				}
+
+				problem.Mod = file.Mod.Slug
+				problem.Version = file.Version.Slug
+				problem.Dependency = dependency.ModID
+				problem.Range = dependency.Range
+
+				result = append(result, problem)
+			}
+		}
+	}
+
+	return result
+}
+
+// checkDependency checks a single dependency against the mod providing it.
+func checkDependency(dependency *JarDependency, provided *providedMod) *BuildProblem {
+	switch dependency.Type {
+	case "required":
+		if provided == nil {
+			return &BuildProblem{
+				Kind:    "missing",
+				Message: fmt.Sprintf("requires %s which is not part of the build", dependency),
+			}
+		}
+
+		fallthrough
+	case "optional":
+		if provided == nil || dependency.Matches(provided.version) {
+			return nil
+		}
+
+		return &BuildProblem{
+			Kind:    "version",
+			Found:   provided.version,
+			Message: fmt.Sprintf("requires %s but %s provides %s", dependency, provided.owner, provided.version),
+		}
+	case "incompatible":
+		if provided == nil || !dependency.Matches(provided.version) {
+			return nil
+		}
+
+		return &BuildProblem{
+			Kind:    "conflict",
+			Found:   provided.version,
+			Message: fmt.Sprintf("is incompatible with %s %s provided by %s", dependency.ModID, provided.version, provided.owner),
+		}
+	}
+
+	return nil
+}
+
+// String returns the mod id together with the range.
+func (d *JarDependency) String() string {
+	if d.Range == "" || d.Range == "*" {
+		return d.ModID
+	}
+
+	return fmt.Sprintf("%s %s", d.ModID, d.Range)
+}
+
+// Matches checks if the version is within the range of the dependency, unknown
+// versions and invalid ranges always match.
+func (d *JarDependency) Matches(version string) bool {
+	if version == "" || strings.Contains(version, "${") {
+		return true
+	}
+
+	if d.fabric {
+		return matchSemverRange(version, d.Range)
+	}
+
+	return matchMavenRange(version, d.Range)
+}
+
+// matchMavenRange checks a version against a Maven version range like
+// [1.0,2.0) as used by Forge. Plain versions are soft requirements and
+// always match.
+func matchMavenRange(version, spec string) bool {
+	spec = strings.TrimSpace(spec)
+
+	if spec == "" || spec == "*" || !strings.ContainsAny(spec, "[(") {
+		return true
+	}
+
+	restrictions := mavenRestrictions.FindAllString(spec, -1)
+
+	if len(restrictions) == 0 {
+		return true
+	}
+
+	for _, restriction := range restrictions {
+		inner := restriction[1 : len(restriction)-1]
+		bounds := strings.SplitN(inner, ",", 2)
+
+		if len(bounds) == 1 {
+			if CompareVersions(version, strings.TrimSpace(bounds[0])) == 0 {
+				return true
+			}
+
+			continue
+		}
+
+		lower := strings.TrimSpace(bounds[0])
+		upper := strings.TrimSpace(bounds[1])
+
+		if lower != "" {
+			res := CompareVersions(version, lower)
+
+			if res < 0 || (res == 0 && restriction[0] == '(') {
+				continue
+			}
+		}
+
+		if upper != "" {
+			res := CompareVersions(version, upper)
+
+			if res > 0 || (res == 0 && restriction[len(restriction)-1] == ')') {
+				continue
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// matchSemverRange checks a version against Fabric version predicates, the
+// predicates of an alternative are separated by spaces and alternatives by ||.
+func matchSemverRange(version, spec string) bool {
+	for _, alternative := range strings.Split(spec, "||") {
+		matched := true
+
+		for _, predicate := range strings.Fields(alternative) {
+			if !matchSemverPredicate(version, predicate) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSemverPredicate checks a version against a single Fabric predicate.
+func matchSemverPredicate(version, predicate string) bool {
+	if predicate == "*" {
+		return true
+	}
+
+	matches := semverOperators.FindStringSubmatch(predicate)
+	operator, target := matches[1], matches[2]
+
+	switch operator {
+	case ">=":
+		return CompareVersions(version, target) >= 0
+	case "<=":
+		return CompareVersions(version, target) <= 0
+	case ">":
+		return CompareVersions(version, target) > 0
+	case "<":
+		return CompareVersions(version, target) < 0
+	case "^", "~":
+		if CompareVersions(version, target) < 0 {
+			return false
+		}
+
+		parts := numericParts(target)
+		current := numericParts(version)
+
+		if operator == "^" && len(parts) > 0 && parts[0] > 0 {
+			return len(current) > 0 && current[0] == parts[0]
+		}
+
+		if len(parts) > 1 {
+			return len(current) > 1 && current[0] == parts[0] && current[1] == parts[1]
+		}
+
+		return len(current) > 0 && len(parts) > 0 && current[0] == parts[0]
+	}
+
+	if strings.ContainsAny(target, "xX*") {
+		current := strings.Split(version, ".")
+
+		for i, part := range strings.Split(target, ".") {
+			if part == "x" || part == "X" || part == "*" {
+				return true
+			}
+
+			if i >= len(current) || CompareVersions(current[i], part) != 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return CompareVersions(version, target) == 0
+}
+
+// numericParts returns the leading numeric components of a version.
+func numericParts(version string) []int64 {
+	result := make([]int64, 0)
+
+	for _, part := range strings.Split(version, ".") {
+		value, err := strconv.ParseInt(part, 10, 64)
+
+		if err != nil {
+			break
+		}
+
+		result = append(result, value)
+	}
+
+	return result
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestMatchMavenRange(t *testing.T) {
+	tests := []struct {
+		version, spec string
+		want          bool
+	}{
+		{"36.1.0", "", true},
+		{"36.1.0", "*", true},
+		{"36.1.0", "36.1.0", true},
+		{"36.1.0", "[36.1,)", true},
+		{"36.0.9", "[36.1,)", false},
+		{"36.1", "(36.1,)", false},
+		{"37.0.0", "[36,37)", false},
+		{"36.9.9", "[36,37)", true},
+		{"37.0.0", "[36,37]", true},
+		{"1.12.2", "[1.12.2]", true},
+		{"1.12.1", "[1.12.2]", false},
+		{"1.12.2", "(,1.12]", false},
+		{"1.10.2", "(,1.11),(1.12,)", true},
+		{"1.11.2", "(,1.11),(1.12,)", false},
+		{"14.23.5.2847", "[14.23.5.2768,)", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchMavenRange(tt.version, tt.spec); got != tt.want {
+			t.Errorf("matchMavenRange(%q, %q): expected %v, got %v", tt.version, tt.spec, tt.want, got)
+		}
+	}
+}
+
+func TestMatchSemverRange(t *testing.T) {
+	tests := []struct {
+		version, spec string
+		want          bool
+	}{
+		{"0.11.3", "*", true},
+		{"0.11.3", ">=0.11.3", true},
+		{"0.11.2", ">=0.11.3", false},
+		{"0.11.2", "<0.11.3", true},
+		{"0.12.0", ">0.11.3 <=0.12.0", true},
+		{"0.12.1", ">0.11.3 <=0.12.0", false},
+		{"1.16.5", "1.16.4 || 1.16.5", true},
+		{"1.16.3", "1.16.4 || 1.16.5", false},
+		{"1.16.5", "1.16.x", true},
+		{"1.17.1", "1.16.x", false},
+		{"1.4.0", "^1.2.0", true},
+		{"2.0.0", "^1.2.0", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.2.2", "~1.2.3", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchSemverRange(tt.version, tt.spec); got != tt.want {
+			t.Errorf("matchSemverRange(%q, %q): expected %v, got %v", tt.version, tt.spec, tt.want, got)
+		}
+	}
+}
+
+func TestCheckSource(t *testing.T) {
+	legacy := testJar(t, map[string][]byte{
+		"mcmod.info": []byte(`[{
+  "modid": "ironchest",
+  "version": "7.0.72",
+  "requiredMods": ["Forge@[14.23.5,)", "JEI@[4.15,)", "Baubles"]
+}]`),
+	})
+
+	jei := testJar(t, map[string][]byte{
+		"mcmod.info": []byte(`[{"modid": "jei", "version": "4.16.0"}]`),
+	})
+
+	source := &ExportSource{
+		Minecraft: &kleister.Minecraft{Version: "1.12.2"},
+		Forge:     &kleister.Forge{Version: "14.23.5.2847", Minecraft: "1.12.2"},
+		Files: []*BuildFile{
+			{Mod: &kleister.Mod{Slug: "ironchest"}, Version: &kleister.Version{Slug: "7.0.72"}, Local: legacy},
+			{Mod: &kleister.Mod{Slug: "jei"}, Version: &kleister.Version{Slug: "4.16.0"}, Local: jei},
+		},
+	}
+
+	got := make([]string, 0)
+
+	for _, problem := range CheckSource(source) {
+		got = append(got, problem.Kind+" "+problem.Dependency)
+	}
+
+	want := []string{"missing Baubles"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
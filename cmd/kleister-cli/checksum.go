@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+// Checksum represents the hashes of a version file.
+type Checksum struct {
+	SHA256 string `json:"sha256" xml:"sha256"`
+	MD5    string `json:"md5" xml:"md5"`
+}
+
+// NewChecksum calculates the hashes of the content.
+func NewChecksum(content []byte) *Checksum {
+	sha := sha256.Sum256(content)
+	sum := md5.Sum(content)
+
+	return &Checksum{
+		SHA256: hex.EncodeToString(sha[:]),
+		MD5:    hex.EncodeToString(sum[:]),
+	}
+}
+
+// FileChecksum calculates the hashes of a file without loading it into memory.
+func FileChecksum(path string) (*Checksum, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file")
+	}
+
+	defer file.Close()
+
+	sha := sha256.New()
+	sum := md5.New()
+
+	if _, err := io.Copy(io.MultiWriter(sha, sum), file); err != nil {
+		return nil, fmt.Errorf("failed to read file")
+	}
+
+	return &Checksum{
+		SHA256: hex.EncodeToString(sha.Sum(nil)),
+		MD5:    hex.EncodeToString(sum.Sum(nil)),
+	}, nil
+}
+
+// UploadChecksum calculates the hashes of a pending file upload.
+func UploadChecksum(file *kleister.Attachment) (*Checksum, error) {
+	if file == nil || file.Upload == "" {
+		return nil, nil
+	}
+
+	content, err := decodeDataURL(file.Upload)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file. %s", err)
+	}
+
+	return NewChecksum(content), nil
+}
+
+// DownloadChecksum calculates the hashes of the file of a version while
+// downloading it, the result gets verified against the MD5 hash provided by
+// the server.
+func DownloadChecksum(version *kleister.Version) (*Checksum, error) {
+	if version.File == nil || version.File.URL == "" {
+		return nil, fmt.Errorf("version has no file")
+	}
+
+	resp, err := http.Get(version.File.URL)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s", version.File.URL)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s, status %d", version.File.URL, resp.StatusCode)
+	}
+
+	sha := sha256.New()
+	sum := md5.New()
+
+	if _, err := io.Copy(io.MultiWriter(sha, sum), resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to download %s", version.File.URL)
+	}
+
+	checksum := &Checksum{
+		SHA256: hex.EncodeToString(sha.Sum(nil)),
+		MD5:    hex.EncodeToString(sum.Sum(nil)),
+	}
+
+	if version.File.MD5 != "" && version.File.MD5 != checksum.MD5 {
+		return nil, fmt.Errorf("checksum mismatch, expected md5 %s but got %s", version.File.MD5, checksum.MD5)
+	}
+
+	return checksum, nil
+}
+
+// FindDuplicate searches a version of the mod with the same file, it relies
+// on the MD5 hash provided by the server. The version with the excluded id
+// gets ignored.
+func FindDuplicate(client kleister.ClientAPI, mod string, checksum *Checksum, exclude int64) (*kleister.Version, error) {
+	records, err := client.VersionList(
+		mod,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.ID == exclude || record.File == nil {
+			continue
+		}
+
+		if record.File.MD5 == checksum.MD5 {
+			return record, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// checkDuplicate prints the hashes of a pending upload and refuses to upload
+// a file already present for the mod unless forced.
+func checkDuplicate(client kleister.ClientAPI, mod string, record *kleister.Version, checksum *Checksum, force bool) error {
+	if checksum == nil {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "file sha256 %s, md5 %s\n", checksum.SHA256, checksum.MD5)
+
+	duplicate, err := FindDuplicate(
+		client,
+		mod,
+		checksum,
+		record.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if duplicate == nil {
+		return nil
+	}
+
+	if !force {
+		return fmt.Errorf("file is already uploaded as version %s, use --force to upload anyway", duplicate.Slug)
+	}
+
+	fmt.Fprintf(os.Stderr, "warning: file is already uploaded as version %s\n", duplicate.Slug)
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+const (
+	testChecksumMD5    = "d87375cead9da0859c2d455fcdab99e9"
+	testChecksumSHA256 = "9f23509c862cb7c1a56dba3c3e7fdda7d56234a868ea0eb9c7dc7611b9f90aa0"
+)
+
+func TestNewChecksum(t *testing.T) {
+	got := NewChecksum([]byte("kleister"))
+
+	if got.MD5 != testChecksumMD5 {
+		t.Errorf("expected md5 %s, got %s", testChecksumMD5, got.MD5)
+	}
+
+	if got.SHA256 != testChecksumSHA256 {
+		t.Errorf("expected sha256 %s, got %s", testChecksumSHA256, got.SHA256)
+	}
+}
+
+func TestDownloadChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mod.jar" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte("kleister"))
+	}))
+
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		file *kleister.Attachment
+		want string
+		fail bool
+	}{
+		{"matching", &kleister.Attachment{URL: server.URL + "/mod.jar", MD5: testChecksumMD5}, testChecksumSHA256, false},
+		{"without md5", &kleister.Attachment{URL: server.URL + "/mod.jar"}, testChecksumSHA256, false},
+		{"mismatch", &kleister.Attachment{URL: server.URL + "/mod.jar", MD5: "invalid"}, "", true},
+		{"missing", &kleister.Attachment{URL: server.URL + "/other.jar"}, "", true},
+		{"without file", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DownloadChecksum(&kleister.Version{File: tt.file})
+
+			if tt.fail {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.SHA256 != tt.want {
+				t.Errorf("expected sha256 %s, got %s", tt.want, got.SHA256)
+			}
+		})
+	}
+}
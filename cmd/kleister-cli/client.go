@@ -1,11 +1,8 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
-	"text/template"
 
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
@@ -51,9 +48,29 @@ func Client() *cli.Command {
 						Usage: "custom output format",
 					},
 					&cli.StringFlag{
-						Name:  "output",
-						Value: "text",
-						Usage: "output as format, json or xml",
+						Name:  "columns",
+						Value: "",
+						Usage: "comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "sort by a field, prefix with a dash for descending order",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Value: "",
+						Usage: "filter by expression on the record fields",
+					},
+					&cli.BoolFlag{
+						Name:  "first",
+						Value: false,
+						Usage: "return only first record",
+					},
+					&cli.BoolFlag{
+						Name:  "last",
+						Value: false,
+						Usage: "return only last record",
 					},
 				},
 				Action: func(c *cli.Context) error {
@@ -75,11 +92,6 @@ func Client() *cli.Command {
 						Value: tmplClientShow,
 						Usage: "custom output format",
 					},
-					&cli.StringFlag{
-						Name:  "output",
-						Value: "text",
-						Usage: "output as format, json or xml",
-					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, ClientShow)
@@ -177,9 +189,29 @@ func Client() *cli.Command {
 								Usage: "custom output format",
 							},
 							&cli.StringFlag{
-								Name:  "output",
-								Value: "text",
-								Usage: "output as format, json or xml",
+								Name:  "columns",
+								Value: "",
+								Usage: "comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "filter by expression on the record fields",
+							},
+							&cli.BoolFlag{
+								Name:  "first",
+								Value: false,
+								Usage: "return only first record",
+							},
+							&cli.BoolFlag{
+								Name:  "last",
+								Value: false,
+								Usage: "return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -241,55 +273,7 @@ func ClientList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	switch c.String("output") {
-	case "json":
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "xml":
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "text":
-		if len(records) == 0 {
-			fmt.Fprintf(os.Stderr, "empty result\n")
-			return nil
-		}
-
-		tmpl, err := template.New(
-			"_",
-		).Funcs(
-			globalFuncMap,
-		).Funcs(
-			sprigFuncMap,
-		).Parse(
-			fmt.Sprintf("%s\n", c.String("format")),
-		)
-
-		if err != nil {
-			return err
-		}
-
-		for _, record := range records {
-			err := tmpl.Execute(os.Stdout, record)
-
-			if err != nil {
-				return err
-			}
-		}
-	default:
-		return fmt.Errorf("invalid output type")
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // ClientShow provides the sub-command to show client details.
@@ -302,44 +286,7 @@ func ClientShow(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	switch c.String("output") {
-	case "json":
-		res, err := json.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "xml":
-		res, err := xml.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "text":
-		tmpl, err := template.New(
-			"_",
-		).Funcs(
-			globalFuncMap,
-		).Funcs(
-			sprigFuncMap,
-		).Parse(
-			fmt.Sprintf("%s\n", c.String("format")),
-		)
-
-		if err != nil {
-			return err
-		}
-
-		return tmpl.Execute(os.Stdout, record)
-	default:
-		return fmt.Errorf("invalid output type")
-	}
-
-	return nil
+	return OutputRecord(c, record)
 }
 
 // ClientDelete provides the sub-command to delete a client.
@@ -444,55 +391,7 @@ func ClientPackList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	switch c.String("output") {
-	case "json":
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "xml":
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-	case "text":
-		if len(records) == 0 {
-			fmt.Fprintf(os.Stderr, "empty result\n")
-			return nil
-		}
-
-		tmpl, err := template.New(
-			"_",
-		).Funcs(
-			globalFuncMap,
-		).Funcs(
-			sprigFuncMap,
-		).Parse(
-			fmt.Sprintf("%s\n", c.String("format")),
-		)
-
-		if err != nil {
-			return err
-		}
-
-		for _, record := range records {
-			err := tmpl.Execute(os.Stdout, record)
-
-			if err != nil {
-				return err
-			}
-		}
-	default:
-		return fmt.Errorf("invalid output type")
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // ClientPackAppend provides the sub-command to append a pack to the client.
@@ -0,0 +1,611 @@
+package main
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strconv"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+// testClient implements the parts of the API used by the commands in memory,
+// calls to other methods panic through the embedded nil interface.
+type testClient struct {
+	kleister.ClientAPI
+
+	packs     []*kleister.Pack
+	builds    []*kleister.Build
+	mods      []*kleister.Mod
+	versions  []*kleister.Version
+	minecraft []*kleister.Minecraft
+	forge     []*kleister.Forge
+	pins      map[int64][]int64
+
+	clients     []*kleister.Client
+	users       []*kleister.User
+	teams       []*kleister.Team
+	packClients []*kleister.ClientPack
+	packUsers   []*kleister.UserPack
+	packTeams   []*kleister.TeamPack
+
+	// fail defines errors returned by methods, keyed by their name.
+	fail map[string]error
+
+	// calls records the names of all mutating calls.
+	calls []string
+
+	next int64
+}
+
+func newTestClient() *testClient {
+	return &testClient{
+		pins: make(map[int64][]int64),
+		fail: make(map[string]error),
+		next: 100,
+	}
+}
+
+func (c *testClient) call(name string) error {
+	if err, ok := c.fail[name]; ok {
+		return err
+	}
+
+	switch name {
+	case "PackPost", "PackPatch", "BuildPost", "BuildPatch", "BuildDelete",
+		"BuildVersionAppend", "BuildVersionDelete", "ModPost", "ModPatch",
+		"VersionPost", "VersionPatch", "VersionDelete", "PackClientAppend",
+		"PackUserAppend", "PackUserPerm", "PackTeamAppend", "PackTeamPerm":
+		c.calls = append(c.calls, name)
+	}
+
+	return nil
+}
+
+func (c *testClient) id() int64 {
+	c.next++
+	return c.next
+}
+
+func testMatch(id int64, slug, key string) bool {
+	return strconv.FormatInt(id, 10) == key || slug == key
+}
+
+func (c *testClient) PackList() ([]*kleister.Pack, error) {
+	result := make([]*kleister.Pack, 0, len(c.packs))
+
+	for _, record := range c.packs {
+		copied := *record
+		result = append(result, &copied)
+	}
+
+	return result, c.call("PackList")
+}
+
+func (c *testClient) PackGet(id string) (*kleister.Pack, error) {
+	if err := c.call("PackGet"); err != nil {
+		return nil, err
+	}
+
+	for _, record := range c.packs {
+		if testMatch(record.ID, record.Slug, id) {
+			copied := *record
+			return &copied, nil
+		}
+	}
+
+	return nil, fmt.Errorf("pack not found")
+}
+
+func (c *testClient) PackPost(in *kleister.Pack) (*kleister.Pack, error) {
+	if err := c.call("PackPost"); err != nil {
+		return nil, err
+	}
+
+	record := *in
+	record.ID = c.id()
+	testAttachments(&record)
+	c.packs = append(c.packs, &record)
+
+	copied := record
+	return &copied, nil
+}
+
+func (c *testClient) PackPatch(in *kleister.Pack) (*kleister.Pack, error) {
+	if err := c.call("PackPatch"); err != nil {
+		return nil, err
+	}
+
+	for i, record := range c.packs {
+		if record.ID == in.ID {
+			updated := *in
+			testAttachments(&updated)
+			c.packs[i] = &updated
+
+			copied := updated
+			return &copied, nil
+		}
+	}
+
+	return nil, fmt.Errorf("pack not found")
+}
+
+func (c *testClient) pack(id string) *kleister.Pack {
+	for _, record := range c.packs {
+		if testMatch(record.ID, record.Slug, id) {
+			return record
+		}
+	}
+
+	return nil
+}
+
+func (c *testClient) build(pack, id string) *kleister.Build {
+	parent := c.pack(pack)
+
+	if parent == nil {
+		return nil
+	}
+
+	for _, record := range c.builds {
+		if record.PackID == parent.ID && testMatch(record.ID, record.Slug, id) {
+			return record
+		}
+	}
+
+	return nil
+}
+
+// testAttachments replaces uploaded data URLs by their checksum like the
+// server stores them.
+func testAttachments(pack *kleister.Pack) {
+	for _, target := range []**kleister.Attachment{&pack.Icon, &pack.Logo, &pack.Background} {
+		if *target == nil || (*target).Upload == "" {
+			continue
+		}
+
+		content, err := decodeDataURL((*target).Upload)
+
+		if err != nil {
+			continue
+		}
+
+		*target = &kleister.Attachment{
+			MD5: fmt.Sprintf("%x", md5.Sum(content)),
+		}
+	}
+}
+
+func (c *testClient) BuildList(pack string) ([]*kleister.Build, error) {
+	if err := c.call("BuildList"); err != nil {
+		return nil, err
+	}
+
+	parent := c.pack(pack)
+
+	if parent == nil {
+		return nil, fmt.Errorf("pack not found")
+	}
+
+	result := make([]*kleister.Build, 0)
+
+	for _, record := range c.builds {
+		if record.PackID == parent.ID {
+			copied := *record
+			result = append(result, &copied)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *testClient) BuildGet(pack, id string) (*kleister.Build, error) {
+	if err := c.call("BuildGet"); err != nil {
+		return nil, err
+	}
+
+	record := c.build(pack, id)
+
+	if record == nil {
+		return nil, fmt.Errorf("build not found")
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+func (c *testClient) BuildPost(pack string, in *kleister.Build) (*kleister.Build, error) {
+	if err := c.call("BuildPost"); err != nil {
+		return nil, err
+	}
+
+	parent := c.pack(pack)
+
+	if parent == nil {
+		return nil, fmt.Errorf("pack not found")
+	}
+
+	record := *in
+	record.ID = c.id()
+	record.PackID = parent.ID
+	c.builds = append(c.builds, &record)
+
+	copied := record
+	return &copied, nil
+}
+
+func (c *testClient) BuildPatch(pack string, in *kleister.Build) (*kleister.Build, error) {
+	if err := c.call("BuildPatch"); err != nil {
+		return nil, err
+	}
+
+	record := c.build(pack, strconv.FormatInt(in.ID, 10))
+
+	if record == nil {
+		return nil, fmt.Errorf("build not found")
+	}
+
+	*record = *in
+
+	copied := *record
+	return &copied, nil
+}
+
+func (c *testClient) BuildDelete(pack, id string) error {
+	if err := c.call("BuildDelete"); err != nil {
+		return err
+	}
+
+	record := c.build(pack, id)
+
+	if record == nil {
+		return fmt.Errorf("build not found")
+	}
+
+	for i := range c.builds {
+		if c.builds[i] == record {
+			c.builds = append(c.builds[:i], c.builds[i+1:]...)
+			break
+		}
+	}
+
+	delete(c.pins, record.ID)
+	return nil
+}
+
+func (c *testClient) BuildVersionList(params kleister.BuildVersionParams) ([]*kleister.BuildVersion, error) {
+	if err := c.call("BuildVersionList"); err != nil {
+		return nil, err
+	}
+
+	record := c.build(params.Pack, params.Build)
+
+	if record == nil {
+		return nil, fmt.Errorf("build not found")
+	}
+
+	result := make([]*kleister.BuildVersion, 0)
+
+	for _, id := range c.pins[record.ID] {
+		for _, version := range c.versions {
+			if version.ID == id {
+				copied := *version
+				result = append(result, &kleister.BuildVersion{Version: &copied})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (c *testClient) BuildVersionAppend(params kleister.BuildVersionParams) error {
+	if err := c.call("BuildVersionAppend"); err != nil {
+		return err
+	}
+
+	record := c.build(params.Pack, params.Build)
+	version := c.version(params.Mod, params.Version)
+
+	if record == nil || version == nil {
+		return fmt.Errorf("build or version not found")
+	}
+
+	c.pins[record.ID] = append(c.pins[record.ID], version.ID)
+	return nil
+}
+
+func (c *testClient) BuildVersionDelete(params kleister.BuildVersionParams) error {
+	if err := c.call("BuildVersionDelete"); err != nil {
+		return err
+	}
+
+	record := c.build(params.Pack, params.Build)
+	version := c.version(params.Mod, params.Version)
+
+	if record == nil || version == nil {
+		return fmt.Errorf("build or version not found")
+	}
+
+	pins := c.pins[record.ID]
+
+	for i, id := range pins {
+		if id == version.ID {
+			c.pins[record.ID] = append(pins[:i], pins[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("version not assigned")
+}
+
+func (c *testClient) mod(id string) *kleister.Mod {
+	for _, record := range c.mods {
+		if testMatch(record.ID, record.Slug, id) {
+			return record
+		}
+	}
+
+	return nil
+}
+
+func (c *testClient) ModList() ([]*kleister.Mod, error) {
+	result := make([]*kleister.Mod, 0, len(c.mods))
+
+	for _, record := range c.mods {
+		copied := *record
+		result = append(result, &copied)
+	}
+
+	return result, c.call("ModList")
+}
+
+func (c *testClient) ModGet(id string) (*kleister.Mod, error) {
+	if err := c.call("ModGet"); err != nil {
+		return nil, err
+	}
+
+	record := c.mod(id)
+
+	if record == nil {
+		return nil, fmt.Errorf("mod not found")
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+func (c *testClient) ModPost(in *kleister.Mod) (*kleister.Mod, error) {
+	if err := c.call("ModPost"); err != nil {
+		return nil, err
+	}
+
+	record := *in
+	record.ID = c.id()
+	c.mods = append(c.mods, &record)
+
+	copied := record
+	return &copied, nil
+}
+
+func (c *testClient) version(mod, id string) *kleister.Version {
+	parent := c.mod(mod)
+
+	if parent == nil {
+		return nil
+	}
+
+	for _, record := range c.versions {
+		if record.ModID == parent.ID && testMatch(record.ID, record.Slug, id) {
+			return record
+		}
+	}
+
+	return nil
+}
+
+func (c *testClient) VersionList(mod string) ([]*kleister.Version, error) {
+	if err := c.call("VersionList"); err != nil {
+		return nil, err
+	}
+
+	parent := c.mod(mod)
+
+	if parent == nil {
+		return nil, fmt.Errorf("mod not found")
+	}
+
+	result := make([]*kleister.Version, 0)
+
+	for _, record := range c.versions {
+		if record.ModID == parent.ID {
+			copied := *record
+			result = append(result, &copied)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *testClient) VersionGet(mod, id string) (*kleister.Version, error) {
+	if err := c.call("VersionGet"); err != nil {
+		return nil, err
+	}
+
+	record := c.version(mod, id)
+
+	if record == nil {
+		return nil, fmt.Errorf("version not found")
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+func (c *testClient) VersionPost(mod string, in *kleister.Version) (*kleister.Version, error) {
+	if err := c.call("VersionPost"); err != nil {
+		return nil, err
+	}
+
+	parent := c.mod(mod)
+
+	if parent == nil {
+		return nil, fmt.Errorf("mod not found")
+	}
+
+	record := *in
+	record.ID = c.id()
+	record.ModID = parent.ID
+	c.versions = append(c.versions, &record)
+
+	copied := record
+	return &copied, nil
+}
+
+func (c *testClient) MinecraftList() ([]*kleister.Minecraft, error) {
+	return c.minecraft, c.call("MinecraftList")
+}
+
+func (c *testClient) MinecraftGet(id string) (*kleister.Minecraft, error) {
+	for _, record := range c.minecraft {
+		if testMatch(record.ID, record.Slug, id) {
+			return record, c.call("MinecraftGet")
+		}
+	}
+
+	return nil, fmt.Errorf("minecraft not found")
+}
+
+func (c *testClient) ForgeList() ([]*kleister.Forge, error) {
+	return c.forge, c.call("ForgeList")
+}
+
+func (c *testClient) ForgeGet(id string) (*kleister.Forge, error) {
+	for _, record := range c.forge {
+		if testMatch(record.ID, record.Slug, id) {
+			return record, c.call("ForgeGet")
+		}
+	}
+
+	return nil, fmt.Errorf("forge not found")
+}
+
+func (c *testClient) ClientList() ([]*kleister.Client, error) {
+	return c.clients, c.call("ClientList")
+}
+
+func (c *testClient) UserList() ([]*kleister.User, error) {
+	return c.users, c.call("UserList")
+}
+
+func (c *testClient) TeamList() ([]*kleister.Team, error) {
+	return c.teams, c.call("TeamList")
+}
+
+func (c *testClient) PackClientList(params kleister.PackClientParams) ([]*kleister.ClientPack, error) {
+	result := make([]*kleister.ClientPack, 0)
+
+	for _, record := range c.packClients {
+		if record.Pack.Slug == params.Pack {
+			copied := *record
+			result = append(result, &copied)
+		}
+	}
+
+	return result, c.call("PackClientList")
+}
+
+func (c *testClient) PackClientAppend(params kleister.PackClientParams) error {
+	if err := c.call("PackClientAppend"); err != nil {
+		return err
+	}
+
+	c.packClients = append(c.packClients, &kleister.ClientPack{
+		Client: &kleister.Client{Slug: params.Client},
+		Pack:   &kleister.Pack{Slug: params.Pack},
+	})
+
+	return nil
+}
+
+func (c *testClient) PackUserList(params kleister.PackUserParams) ([]*kleister.UserPack, error) {
+	result := make([]*kleister.UserPack, 0)
+
+	for _, record := range c.packUsers {
+		if record.Pack.Slug == params.Pack {
+			copied := *record
+			result = append(result, &copied)
+		}
+	}
+
+	return result, c.call("PackUserList")
+}
+
+func (c *testClient) PackUserAppend(params kleister.PackUserParams) error {
+	if err := c.call("PackUserAppend"); err != nil {
+		return err
+	}
+
+	c.packUsers = append(c.packUsers, &kleister.UserPack{
+		User: &kleister.User{Slug: params.User},
+		Pack: &kleister.Pack{Slug: params.Pack},
+		Perm: params.Perm,
+	})
+
+	return nil
+}
+
+func (c *testClient) PackUserPerm(params kleister.PackUserParams) error {
+	if err := c.call("PackUserPerm"); err != nil {
+		return err
+	}
+
+	for _, record := range c.packUsers {
+		if record.Pack.Slug == params.Pack && record.User.Slug == params.User {
+			record.Perm = params.Perm
+			return nil
+		}
+	}
+
+	return fmt.Errorf("user not assigned")
+}
+
+func (c *testClient) PackTeamList(params kleister.PackTeamParams) ([]*kleister.TeamPack, error) {
+	result := make([]*kleister.TeamPack, 0)
+
+	for _, record := range c.packTeams {
+		if record.Pack.Slug == params.Pack {
+			copied := *record
+			result = append(result, &copied)
+		}
+	}
+
+	return result, c.call("PackTeamList")
+}
+
+func (c *testClient) PackTeamAppend(params kleister.PackTeamParams) error {
+	if err := c.call("PackTeamAppend"); err != nil {
+		return err
+	}
+
+	c.packTeams = append(c.packTeams, &kleister.TeamPack{
+		Team: &kleister.Team{Slug: params.Team},
+		Pack: &kleister.Pack{Slug: params.Pack},
+		Perm: params.Perm,
+	})
+
+	return nil
+}
+
+func (c *testClient) PackTeamPerm(params kleister.PackTeamParams) error {
+	if err := c.call("PackTeamPerm"); err != nil {
+		return err
+	}
+
+	for _, record := range c.packTeams {
+		if record.Pack.Slug == params.Pack && record.Team.Slug == params.Team {
+			record.Perm = params.Perm
+			return nil
+		}
+	}
+
+	return fmt.Errorf("team not assigned")
+}
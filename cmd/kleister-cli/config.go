@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config represents the local configuration file of the client.
+type Config struct {
+	Current  string           `yaml:"current,omitempty"`
+	Contexts []*ConfigContext `yaml:"contexts,omitempty"`
+}
+
+// ConfigContext represents a named server within the configuration.
+type ConfigContext struct {
+	Name    string `json:"name" xml:"name" yaml:"name"`
+	Server  string `json:"server" xml:"server" yaml:"server"`
+	Token   string `json:"-" xml:"-" yaml:"token,omitempty"`
+	Current bool   `json:"current" xml:"current" yaml:"-"`
+}
+
+// ConfigPath returns the path of the configuration file, it respects the
+// XDG_CONFIG_HOME environment variable.
+func ConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "kleister", "config.yml")
+	}
+
+	for _, env := range []string{"HOME", "USERPROFILE"} {
+		if home := os.Getenv(env); home != "" {
+			return filepath.Join(home, ".config", "kleister", "config.yml")
+		}
+	}
+
+	return filepath.Join(".kleister", "config.yml")
+}
+
+// LoadConfig reads the configuration file, a missing file results in an
+// empty configuration.
+func LoadConfig() (*Config, error) {
+	result := &Config{}
+	content, err := ioutil.ReadFile(ConfigPath())
+
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config")
+	}
+
+	if err := yaml.Unmarshal(content, result); err != nil {
+		return nil, fmt.Errorf("failed to parse config. %s", err)
+	}
+
+	for _, context := range result.Contexts {
+		context.Current = context.Name == result.Current
+	}
+
+	return result, nil
+}
+
+// Save writes the configuration file, it's only readable by the owner as it
+// contains tokens.
+func (c *Config) Save() error {
+	path := ConfigPath()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config dir")
+	}
+
+	content, err := yaml.Marshal(c)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode config. %s", err)
+	}
+
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write config")
+	}
+
+	return os.Chmod(path, 0600)
+}
+
+// Get returns the context with the name or nil if it doesn't exist.
+func (c *Config) Get(name string) *ConfigContext {
+	for _, context := range c.Contexts {
+		if context.Name == name {
+			return context
+		}
+	}
+
+	return nil
+}
+
+// Active returns the currently selected context or nil.
+func (c *Config) Active() *ConfigContext {
+	if c.Current == "" {
+		return nil
+	}
+
+	return c.Get(c.Current)
+}
+
+// Set adds the context or replaces an existing one with the same name.
+func (c *Config) Set(context *ConfigContext) {
+	for i, row := range c.Contexts {
+		if row.Name == context.Name {
+			c.Contexts[i] = context
+			return
+		}
+	}
+
+	c.Contexts = append(c.Contexts, context)
+}
+
+// Remove deletes the context with the name, it returns false if it doesn't exist.
+func (c *Config) Remove(name string) bool {
+	for i, row := range c.Contexts {
+		if row.Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+
+			if c.Current == name {
+				c.Current = ""
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v2"
+)
+
+// tmplContextList represents a row within context listing.
+var tmplContextList = "Name: \x1b[33m{{ .Name }}\x1b[0m" + `
+Server: {{ .Server }}
+Current: {{ .Current }}
+`
+
+// tmplContextShow represents a context within details view.
+var tmplContextShow = "Name: \x1b[33m{{ .Name }}\x1b[0m" + `
+Server: {{ .Server }}
+Token: {{ if .Token }}present{{ else }}missing{{ end }}
+Current: {{ .Current }}
+`
+
+// ConfigFunc is the real config handle implementation.
+type ConfigFunc func(c *cli.Context, config *Config) error
+
+// HandleConfig wraps the command function handler for local configuration.
+func HandleConfig(c *cli.Context, fn ConfigFunc) error {
+	config, err := LoadConfig()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if err := fn(c, config); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+// Context provides the sub-command to manage server contexts.
+func Context() *cli.Command {
+	return &cli.Command{
+		Name:  "context",
+		Usage: "manage named server contexts",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "list",
+				Aliases:   []string{"ls"},
+				Usage:     "list all contexts",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Value: tmplContextList,
+						Usage: "custom output format",
+					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "sort by a field, prefix with a dash for descending order",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return HandleConfig(c, ContextList)
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "display a context, defaults to the current one",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Value: "",
+						Usage: "name of the context to show",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: tmplContextShow,
+						Usage: "custom output format",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return HandleConfig(c, ContextShow)
+				},
+			},
+			{
+				Name:      "add",
+				Usage:     "add or replace a context",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Value: "",
+						Usage: "name of the context",
+					},
+					&cli.StringFlag{
+						Name:  "server",
+						Value: "",
+						Usage: "api server of the context",
+					},
+					&cli.StringFlag{
+						Name:  "token",
+						Value: "",
+						Usage: "api token of the context",
+					},
+					&cli.BoolFlag{
+						Name:  "use",
+						Value: false,
+						Usage: "switch to the context",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return HandleConfig(c, ContextAdd)
+				},
+			},
+			{
+				Name:      "use",
+				Usage:     "switch the current context",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Value: "",
+						Usage: "name of the context to use",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return HandleConfig(c, ContextUse)
+				},
+			},
+			{
+				Name:      "remove",
+				Aliases:   []string{"rm"},
+				Usage:     "remove a context",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Value: "",
+						Usage: "name of the context to remove",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return HandleConfig(c, ContextRemove)
+				},
+			},
+		},
+	}
+}
+
+// ContextList provides the sub-command to list all contexts.
+func ContextList(c *cli.Context, config *Config) error {
+	return OutputList(c, config.Contexts)
+}
+
+// ContextShow provides the sub-command to show context details.
+func ContextShow(c *cli.Context, config *Config) error {
+	record := config.Active()
+
+	if c.String("name") != "" {
+		record = config.Get(c.String("name"))
+	}
+
+	if record == nil {
+		return fmt.Errorf("context not found")
+	}
+
+	return OutputRecord(c, record)
+}
+
+// ContextAdd provides the sub-command to add a context.
+func ContextAdd(c *cli.Context, config *Config) error {
+	record := &ConfigContext{}
+
+	if val := c.String("name"); val != "" {
+		record.Name = val
+	} else {
+		return fmt.Errorf("you must provide a name")
+	}
+
+	if val := c.String("server"); val != "" {
+		record.Server = val
+	} else {
+		return fmt.Errorf("you must provide a server")
+	}
+
+	if val := c.String("token"); val != "" {
+		record.Token = val
+	}
+
+	config.Set(record)
+
+	if c.Bool("use") || len(config.Contexts) == 1 {
+		config.Current = record.Name
+	}
+
+	if err := config.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully added\n")
+	return nil
+}
+
+// ContextUse provides the sub-command to switch the current context.
+func ContextUse(c *cli.Context, config *Config) error {
+	if c.String("name") == "" {
+		return fmt.Errorf("you must provide a name")
+	}
+
+	if config.Get(c.String("name")) == nil {
+		return fmt.Errorf("context not found")
+	}
+
+	config.Current = c.String("name")
+
+	if err := config.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "switched to context %s\n", config.Current)
+	return nil
+}
+
+// ContextRemove provides the sub-command to remove a context.
+func ContextRemove(c *cli.Context, config *Config) error {
+	if c.String("name") == "" {
+		return fmt.Errorf("you must provide a name")
+	}
+
+	if !config.Remove(c.String("name")) {
+		return fmt.Errorf("context not found")
+	}
+
+	if err := config.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully removed\n")
+	return nil
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/yaml.v2"
+)
+
+// curseDownloadURL defines the default URL to download CurseForge files, the
+// {project} and {file} placeholders get replaced by the ids.
+const curseDownloadURL = "https://www.curseforge.com/api/v1/mods/{project}/files/{file}/download"
+
+// CurseManifest represents the manifest.json of a CurseForge modpack.
+type CurseManifest struct {
+	Minecraft struct {
+		Version    string         `json:"version"`
+		ModLoaders []*CurseLoader `json:"modLoaders"`
+	} `json:"minecraft"`
+	ManifestType    string       `json:"manifestType"`
+	ManifestVersion int          `json:"manifestVersion"`
+	Name            string       `json:"name"`
+	Version         string       `json:"version"`
+	Author          string       `json:"author"`
+	Files           []*CurseFile `json:"files"`
+	Overrides       string       `json:"overrides"`
+}
+
+// CurseLoader represents a mod loader within a modpack.
+type CurseLoader struct {
+	ID      string `json:"id"`
+	Primary bool   `json:"primary"`
+}
+
+// CurseFile represents a file hosted on CurseForge within a modpack.
+type CurseFile struct {
+	ProjectID int64 `json:"projectID"`
+	FileID    int64 `json:"fileID"`
+	Required  bool  `json:"required"`
+}
+
+// CurseMapping maps mod slugs to CurseForge projects, it's stored as YAML or
+// JSON file like:
+//
+//	jei:
+//	  project: 238222
+//	  files:
+//	    4.16.0: 2724420
+type CurseMapping map[string]*CurseProject
+
+// CurseProject represents the CurseForge ids of a mod and its versions.
+type CurseProject struct {
+	Project int64            `json:"project" yaml:"project"`
+	Files   map[string]int64 `json:"files" yaml:"files"`
+}
+
+// LoadCurseMapping reads a mapping file, a missing file results in an empty
+// mapping.
+func LoadCurseMapping(file string) (CurseMapping, error) {
+	result := make(CurseMapping)
+
+	if file == "" {
+		return result, nil
+	}
+
+	content, err := ioutil.ReadFile(file)
+
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping %s", file)
+	}
+
+	if err := yaml.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping %s. %s", file, err)
+	}
+
+	return result, nil
+}
+
+// Write stores the mapping as YAML file.
+func (m CurseMapping) Write(file string) error {
+	content, err := yaml.Marshal(m)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode mapping. %s", err)
+	}
+
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write mapping %s", file)
+	}
+
+	return nil
+}
+
+// Lookup returns the CurseForge file for a mod version if it's known.
+func (m CurseMapping) Lookup(mod, version string) *CurseFile {
+	project, ok := m[mod]
+
+	if !ok || project.Project == 0 {
+		return nil
+	}
+
+	id, ok := project.Files[version]
+
+	if !ok || id == 0 {
+		return nil
+	}
+
+	return &CurseFile{
+		ProjectID: project.Project,
+		FileID:    id,
+		Required:  true,
+	}
+}
+
+// Find returns the version pin recorded for a CurseForge file.
+func (m CurseMapping) Find(file *CurseFile) (string, bool) {
+	for mod, project := range m {
+		if project.Project != file.ProjectID {
+			continue
+		}
+
+		for version, id := range project.Files {
+			if id == file.FileID {
+				return fmt.Sprintf("%s@%s", mod, version), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Store records the CurseForge ids of a mod version.
+func (m CurseMapping) Store(mod, version string, file *CurseFile) {
+	project, ok := m[mod]
+
+	if !ok {
+		project = &CurseProject{
+			Project: file.ProjectID,
+		}
+
+		m[mod] = project
+	}
+
+	if project.Files == nil {
+		project.Files = make(map[string]int64)
+	}
+
+	project.Files[version] = file.FileID
+}
+
+// writeCurseForge writes the build as CurseForge modpack. Versions with known
+// CurseForge ids are referenced within the manifest, all other version files
+// are bundled as overrides.
+func writeCurseForge(source *ExportSource, archive *zip.Writer) error {
+	manifest := &CurseManifest{
+		ManifestType:    "minecraftModpack",
+		ManifestVersion: 1,
+		Name:            source.Pack.Name,
+		Version:         source.Build.Name,
+		Files:           make([]*CurseFile, 0),
+		Overrides:       "overrides",
+	}
+
+	if source.Minecraft != nil {
+		manifest.Minecraft.Version = source.Minecraft.Version
+	}
+
+	if source.Forge != nil {
+		manifest.Minecraft.ModLoaders = append(manifest.Minecraft.ModLoaders, &CurseLoader{
+			ID:      "forge-" + ForgeVersion(source.Forge),
+			Primary: true,
+		})
+	}
+
+	for _, file := range source.Files {
+		if related := source.Projects.Lookup(file.Mod.Slug, file.Version.Slug); related != nil {
+			manifest.Files = append(manifest.Files, related)
+			continue
+		}
+
+		if err := addVersionFile(archive, manifest.Overrides, file); err != nil {
+			return err
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest.json. %s", err)
+	}
+
+	return addArchiveFile(archive, "manifest.json", append(content, '\n'))
+}
+
+// CurseImport represents the options to import a CurseForge modpack.
+type CurseImport struct {
+	File     string
+	Pack     string
+	Build    string
+	Download string
+	Mapping  CurseMapping
+	Force    bool
+
+	// SkipOverrides imports the pack without override files outside of the
+	// mods folder, kleister doesn't provide a place for them.
+	SkipOverrides bool
+}
+
+// ImportCurseForge reads a CurseForge modpack and creates the pack, the mods
+// and versions of all contained jars and a build pinning them. Files hosted
+// on CurseForge get downloaded and their ids recorded within the mapping.
+func ImportCurseForge(client kleister.ClientAPI, opts *CurseImport) (*kleister.Build, error) {
+	archive, err := zip.OpenReader(opts.File)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s", opts.File)
+	}
+
+	defer archive.Close()
+
+	manifest := &CurseManifest{}
+	overrides := make([]*zip.File, 0)
+
+	for _, file := range archive.File {
+		if file.Name != "manifest.json" {
+			continue
+		}
+
+		content, err := readZipFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(content, manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest.json. %s", err)
+		}
+	}
+
+	if manifest.ManifestType != "minecraftModpack" {
+		return nil, fmt.Errorf("failed to find a CurseForge manifest within %s", opts.File)
+	}
+
+	if manifest.Overrides == "" {
+		manifest.Overrides = "overrides"
+	}
+
+	skipped := make([]string, 0)
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.HasPrefix(file.Name, manifest.Overrides+"/") {
+			continue
+		}
+
+		if path.Dir(file.Name) == manifest.Overrides+"/mods" && strings.HasSuffix(file.Name, ".jar") {
+			overrides = append(overrides, file)
+		} else {
+			skipped = append(skipped, file.Name)
+		}
+	}
+
+	if len(skipped) > 0 && !opts.SkipOverrides {
+		return nil, fmt.Errorf("failed to import %d override files outside of the mods folder like %s, use --skip-overrides to import without them", len(skipped), skipped[0])
+	}
+
+	for _, name := range skipped {
+		fmt.Fprintf(os.Stderr, "warning: skipping override file %s\n", name)
+	}
+
+	definition := &ManifestBuild{
+		Slug: opts.Build,
+		Name: manifest.Version,
+	}
+
+	if definition.Slug == "" {
+		definition.Slug = Slugify(manifest.Version)
+	}
+
+	if definition.Name == "" {
+		definition.Name = definition.Slug
+	}
+
+	if definition.Minecraft, definition.Forge, err = findCurseLoader(client, manifest); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "import")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory")
+	}
+
+	defer os.RemoveAll(dir)
+
+	records, err := client.ModList()
+
+	if err != nil {
+		return nil, err
+	}
+
+	mods := make(map[string]*kleister.Mod, len(records))
+
+	for _, record := range records {
+		mods[record.Slug] = record
+	}
+
+	failed := 0
+
+	for _, file := range overrides {
+		content, err := readZipFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		local := filepath.Join(dir, path.Base(file.Name))
+
+		if err := ioutil.WriteFile(local, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to extract %s", file.Name)
+		}
+
+		pin, err := importJar(client, mods, local, opts.Force)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+			failed++
+
+			continue
+		}
+
+		definition.Versions = append(definition.Versions, pin)
+	}
+
+	for _, file := range manifest.Files {
+		if pin, ok := opts.Mapping.Find(file); ok {
+			mod, version, _ := ParseVersionPin(pin)
+
+			if _, err := client.VersionGet(mod, version); err == nil {
+				fmt.Fprintf(os.Stderr, "version %s already exists\n", pin)
+				definition.Versions = append(definition.Versions, pin)
+
+				continue
+			}
+		}
+
+		local := filepath.Join(dir, fmt.Sprintf("%d-%d.jar", file.ProjectID, file.FileID))
+
+		source := strings.NewReplacer(
+			"{project}", strconv.FormatInt(file.ProjectID, 10),
+			"{file}", strconv.FormatInt(file.FileID, 10),
+		).Replace(opts.Download)
+
+		content, err := downloadAsset(source)
+
+		if err == nil {
+			err = ioutil.WriteFile(local, content, 0644)
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to download file %d of project %d. %s\n", file.FileID, file.ProjectID, err)
+			failed++
+
+			continue
+		}
+
+		pin, err := importJar(client, mods, local, opts.Force)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+			failed++
+
+			continue
+		}
+
+		mod, version, _ := ParseVersionPin(pin)
+		opts.Mapping.Store(mod, version, file)
+
+		definition.Versions = append(definition.Versions, pin)
+	}
+
+	if failed > 0 {
+		return nil, fmt.Errorf("failed to import %d of %d mods, the imported ones get reused by the next import", failed, len(overrides)+len(manifest.Files))
+	}
+
+	if opts.Pack == "" {
+		opts.Pack = Slugify(manifest.Name)
+	}
+
+	pack, err := findPack(client, opts.Pack)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if pack == nil {
+		pack, err = client.PackPost(
+			&kleister.Pack{
+				Slug: opts.Pack,
+				Name: manifest.Name,
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(os.Stderr, "created pack %s\n", pack.Slug)
+	}
+
+	existing, err := findBuild(client, pack.Slug, definition.Slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		definition.MinJava = existing.MinJava
+		definition.MinMemory = existing.MinMemory
+		definition.Published = existing.Published
+		definition.Private = existing.Private
+	}
+
+	return applyManifestBuild(client, pack, definition)
+}
+
+// findCurseLoader resolves the slugs of the Minecraft and Forge versions
+// defined by a CurseForge manifest.
+func findCurseLoader(client kleister.ClientAPI, manifest *CurseManifest) (string, string, error) {
+	minecraft := ""
+
+	if manifest.Minecraft.Version != "" {
+		records, err := client.MinecraftList()
+
+		if err != nil {
+			return "", "", err
+		}
+
+		for _, record := range records {
+			if record.Version == manifest.Minecraft.Version {
+				minecraft = record.Slug
+			}
+		}
+
+		if minecraft == "" {
+			return "", "", fmt.Errorf("unknown minecraft version %s", manifest.Minecraft.Version)
+		}
+	}
+
+	for _, loader := range manifest.Minecraft.ModLoaders {
+		if !strings.HasPrefix(loader.ID, "forge-") {
+			fmt.Fprintf(os.Stderr, "warning: skipping unsupported mod loader %s\n", loader.ID)
+			continue
+		}
+
+		version := strings.TrimPrefix(loader.ID, "forge-")
+
+		records, err := client.ForgeList()
+
+		if err != nil {
+			return "", "", err
+		}
+
+		for _, record := range records {
+			if ForgeVersion(record) == version && (record.Minecraft == "" || record.Minecraft == manifest.Minecraft.Version) {
+				return minecraft, record.Slug, nil
+			}
+		}
+
+		return "", "", fmt.Errorf("unknown forge version %s", version)
+	}
+
+	return minecraft, "", nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCurseMapping(t *testing.T) {
+	mapping := CurseMapping{}
+	mapping.Store("jei", "4.16.0", &CurseFile{ProjectID: 238222, FileID: 2724420})
+	mapping.Store("jei", "4.15.0", &CurseFile{ProjectID: 238222, FileID: 2700000})
+
+	tests := []struct {
+		file *CurseFile
+		pin  string
+		ok   bool
+	}{
+		{&CurseFile{ProjectID: 238222, FileID: 2724420}, "jei@4.16.0", true},
+		{&CurseFile{ProjectID: 238222, FileID: 2700000}, "jei@4.15.0", true},
+		{&CurseFile{ProjectID: 238222, FileID: 1}, "", false},
+		{&CurseFile{ProjectID: 1, FileID: 2724420}, "", false},
+	}
+
+	for _, tt := range tests {
+		pin, ok := mapping.Find(tt.file)
+
+		if pin != tt.pin || ok != tt.ok {
+			t.Errorf("Find(%d, %d): expected %q %v, got %q %v", tt.file.ProjectID, tt.file.FileID, tt.pin, tt.ok, pin, ok)
+		}
+	}
+
+	if got := mapping.Lookup("jei", "4.16.0"); got == nil || got.FileID != 2724420 {
+		t.Errorf("Lookup(jei, 4.16.0): expected file 2724420, got %+v", got)
+	}
+
+	if got := mapping.Lookup("jei", "4.14.0"); got != nil {
+		t.Errorf("Lookup(jei, 4.14.0): expected nil, got %+v", got)
+	}
+}
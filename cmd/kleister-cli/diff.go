@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+// BuildDelta represents the differences between two builds.
+type BuildDelta struct {
+	From    string           `json:"from" xml:"from"`
+	To      string           `json:"to" xml:"to"`
+	Fields  []*FieldChange   `json:"fields" xml:"fields>field"`
+	Added   []*VersionChange `json:"added" xml:"added>version"`
+	Removed []*VersionChange `json:"removed" xml:"removed>version"`
+	Changed []*VersionChange `json:"changed" xml:"changed>version"`
+}
+
+// Empty checks if the builds don't differ at all.
+func (d *BuildDelta) Empty() bool {
+	return len(d.Fields) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// FieldChange represents a changed setting of a build.
+type FieldChange struct {
+	Field string `json:"field" xml:"field"`
+	From  string `json:"from" xml:"from"`
+	To    string `json:"to" xml:"to"`
+}
+
+// VersionChange represents an added, removed or changed mod version.
+type VersionChange struct {
+	Mod  string `json:"mod" xml:"mod"`
+	From string `json:"from,omitempty" xml:"from,omitempty"`
+	To   string `json:"to,omitempty" xml:"to,omitempty"`
+}
+
+// LoadBuildSide loads one side of a comparison, it's either a build id or
+// slug of the pack or a manifest file. A build within a manifest with
+// multiple builds gets selected by appending it like manifest.yml#1.0.0.
+func LoadBuildSide(client kleister.ClientAPI, resolver *ModResolver, pack, value string, manifest bool) (*ManifestBuild, error) {
+	file, selector := value, ""
+
+	if i := strings.LastIndex(value, "#"); i >= 0 {
+		file, selector = value[:i], value[i+1:]
+	}
+
+	if manifest || isManifestPath(file) {
+		definition, err := LoadManifest(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if selector == "" {
+			if len(definition.Builds) != 1 {
+				return nil, fmt.Errorf("manifest %s defines multiple builds, select one like %s#<build>", file, file)
+			}
+
+			return definition.Builds[0], nil
+		}
+
+		for _, build := range definition.Builds {
+			if build.Slug == selector {
+				return build, nil
+			}
+		}
+
+		return nil, fmt.Errorf("manifest %s doesn't define build %s", file, selector)
+	}
+
+	_, definition, err := ExportBuild(
+		client,
+		resolver,
+		pack,
+		value,
+	)
+
+	return definition, err
+}
+
+// isManifestPath checks if a value looks like a manifest file, otherwise a
+// build slug that matches a local file would be loaded from disk.
+func isManifestPath(value string) bool {
+	if strings.ContainsAny(value, "/"+string(os.PathSeparator)) {
+		return true
+	}
+
+	switch strings.ToLower(filepath.Ext(value)) {
+	case ".yml", ".yaml", ".json", ".toml":
+		return true
+	}
+
+	return false
+}
+
+// DiffBuilds compares the settings and versions of two builds.
+func DiffBuilds(from, to *ManifestBuild) *BuildDelta {
+	result := &BuildDelta{
+		From:    from.Slug,
+		To:      to.Slug,
+		Fields:  make([]*FieldChange, 0),
+		Added:   make([]*VersionChange, 0),
+		Removed: make([]*VersionChange, 0),
+		Changed: make([]*VersionChange, 0),
+	}
+
+	for _, field := range []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"Minecraft", from.Minecraft, to.Minecraft},
+		{"Forge", from.Forge, to.Forge},
+		{"Java", from.MinJava, to.MinJava},
+		{"Memory", from.MinMemory, to.MinMemory},
+	} {
+		if field.from != field.to {
+			result.Fields = append(result.Fields, &FieldChange{
+				Field: field.name,
+				From:  field.from,
+				To:    field.to,
+			})
+		}
+	}
+
+	before := pinnedVersions(from)
+	after := pinnedVersions(to)
+
+	for mod, version := range after {
+		previous, ok := before[mod]
+
+		if !ok {
+			result.Added = append(result.Added, &VersionChange{
+				Mod: mod,
+				To:  version,
+			})
+		} else if previous != version {
+			result.Changed = append(result.Changed, &VersionChange{
+				Mod:  mod,
+				From: previous,
+				To:   version,
+			})
+		}
+	}
+
+	for mod, version := range before {
+		if _, ok := after[mod]; !ok {
+			result.Removed = append(result.Removed, &VersionChange{
+				Mod:  mod,
+				From: version,
+			})
+		}
+	}
+
+	for _, changes := range [][]*VersionChange{result.Added, result.Removed, result.Changed} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Mod < changes[j].Mod
+		})
+	}
+
+	return result
+}
+
+// pinnedVersions maps the mods of a build to the pinned versions.
+func pinnedVersions(build *ManifestBuild) map[string]string {
+	result := make(map[string]string, len(build.Versions))
+
+	for _, pin := range build.Versions {
+		mod, version, err := ParseVersionPin(pin)
+
+		if err != nil {
+			continue
+		}
+
+		result[mod] = version
+	}
+
+	return result
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestDiffBuilds(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    *ManifestBuild
+		to      *ManifestBuild
+		fields  []FieldChange
+		added   []VersionChange
+		removed []VersionChange
+		changed []VersionChange
+	}{
+		{
+			name: "identical",
+			from: &ManifestBuild{Slug: "1.0.0", Minecraft: "1.12.2", Versions: []string{"jei@4.15.0"}},
+			to:   &ManifestBuild{Slug: "1.0.1", Minecraft: "1.12.2", Versions: []string{"jei@4.15.0"}},
+		},
+		{
+			name:  "added",
+			from:  &ManifestBuild{Slug: "1.0.0", Versions: []string{"jei@4.15.0"}},
+			to:    &ManifestBuild{Slug: "1.0.1", Versions: []string{"jei@4.15.0", "baubles@1.5.2", "appeng@rv6"}},
+			added: []VersionChange{{Mod: "appeng", To: "rv6"}, {Mod: "baubles", To: "1.5.2"}},
+		},
+		{
+			name:    "removed",
+			from:    &ManifestBuild{Slug: "1.0.0", Versions: []string{"jei@4.15.0", "baubles@1.5.2"}},
+			to:      &ManifestBuild{Slug: "1.0.1", Versions: []string{"jei@4.15.0"}},
+			removed: []VersionChange{{Mod: "baubles", From: "1.5.2"}},
+		},
+		{
+			name:    "changed",
+			from:    &ManifestBuild{Slug: "1.0.0", Versions: []string{"jei@4.15.0", "baubles@1.5.2"}},
+			to:      &ManifestBuild{Slug: "1.0.1", Versions: []string{"jei@4.16.0", "baubles@1.5.2"}},
+			changed: []VersionChange{{Mod: "jei", From: "4.15.0", To: "4.16.0"}},
+		},
+		{
+			name:    "mixed",
+			from:    &ManifestBuild{Slug: "1.0.0", Versions: []string{"jei@4.15.0", "baubles@1.5.2"}},
+			to:      &ManifestBuild{Slug: "1.0.1", Versions: []string{"jei@4.16.0", "appeng@rv6"}},
+			added:   []VersionChange{{Mod: "appeng", To: "rv6"}},
+			removed: []VersionChange{{Mod: "baubles", From: "1.5.2"}},
+			changed: []VersionChange{{Mod: "jei", From: "4.15.0", To: "4.16.0"}},
+		},
+		{
+			name: "fields",
+			from: &ManifestBuild{Slug: "1.0.0", Minecraft: "1.12.2", Forge: "14.23.5.2768", MinJava: "1.8", MinMemory: "2048"},
+			to:   &ManifestBuild{Slug: "1.0.1", Minecraft: "1.12.2", Forge: "14.23.5.2847", MinJava: "1.8", MinMemory: "4096"},
+			fields: []FieldChange{
+				{Field: "Forge", From: "14.23.5.2768", To: "14.23.5.2847"},
+				{Field: "Memory", From: "2048", To: "4096"},
+			},
+		},
+		{
+			name: "invalid pins",
+			from: &ManifestBuild{Slug: "1.0.0", Versions: []string{"jei"}},
+			to:   &ManifestBuild{Slug: "1.0.1", Versions: []string{"@4.15.0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := DiffBuilds(tt.from, tt.to)
+
+		if got.From != tt.from.Slug || got.To != tt.to.Slug {
+			t.Errorf("%s: expected %s to %s, got %s to %s", tt.name, tt.from.Slug, tt.to.Slug, got.From, got.To)
+		}
+
+		if want := len(tt.fields) == 0 && len(tt.added) == 0 && len(tt.removed) == 0 && len(tt.changed) == 0; got.Empty() != want {
+			t.Errorf("%s: expected empty %v, got %v", tt.name, want, got.Empty())
+		}
+
+		fields := make([]FieldChange, 0)
+
+		for _, field := range got.Fields {
+			fields = append(fields, *field)
+		}
+
+		if len(fields) != len(tt.fields) || (len(fields) > 0 && !reflect.DeepEqual(fields, tt.fields)) {
+			t.Errorf("%s: expected fields %v, got %v", tt.name, tt.fields, fields)
+		}
+
+		for _, list := range []struct {
+			kind string
+			want []VersionChange
+			got  []*VersionChange
+		}{
+			{"added", tt.added, got.Added},
+			{"removed", tt.removed, got.Removed},
+			{"changed", tt.changed, got.Changed},
+		} {
+			changes := make([]VersionChange, 0)
+
+			for _, change := range list.got {
+				changes = append(changes, *change)
+			}
+
+			if len(changes) != len(list.want) || (len(changes) > 0 && !reflect.DeepEqual(changes, list.want)) {
+				t.Errorf("%s: expected %s %v, got %v", tt.name, list.kind, list.want, changes)
+			}
+		}
+	}
+}
+
+func TestIsManifestPath(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"1.0.0", false},
+		{"stable", false},
+		{"manifest", false},
+		{"manifest.yml", true},
+		{"manifest.YAML", true},
+		{"manifest.json", true},
+		{"manifest.toml", true},
+		{"./manifest", true},
+		{"packs/manifest", true},
+	}
+
+	for _, tt := range tests {
+		if got := isManifestPath(tt.value); got != tt.want {
+			t.Errorf("isManifestPath(%q): expected %v, got %v", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestLoadBuildSide(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kleister-diff")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	cwd, err := os.Getwd()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chdir(cwd)
+
+	content := []byte("slug: pack\nname: Pack\nbuilds:\n- slug: local\n  versions:\n  - jei@4.16.0\n")
+
+	for _, name := range []string{"stable", "manifest.yml"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := newTestClient()
+	client.packs = []*kleister.Pack{{ID: 1, Slug: "demo"}}
+	client.builds = []*kleister.Build{{ID: 2, PackID: 1, Slug: "stable"}}
+
+	tests := []struct {
+		value    string
+		manifest bool
+		want     string
+	}{
+		{"stable", false, "stable"},
+		{"stable", true, "local"},
+		{"./stable", false, "local"},
+		{"manifest.yml", false, "local"},
+		{"manifest.yml#local", false, "local"},
+	}
+
+	for _, tt := range tests {
+		got, err := LoadBuildSide(client, NewModResolver(client), "demo", tt.value, tt.manifest)
+
+		if err != nil {
+			t.Errorf("LoadBuildSide(%q, %v): unexpected error %s", tt.value, tt.manifest, err)
+			continue
+		}
+
+		if got.Slug != tt.want {
+			t.Errorf("LoadBuildSide(%q, %v): expected %s, got %s", tt.value, tt.manifest, tt.want, got.Slug)
+		}
+	}
+
+	if _, err := LoadBuildSide(client, NewModResolver(client), "demo", "manifest.yml#missing", false); err == nil {
+		t.Errorf("expected an error for an unknown build within the manifest")
+	}
+}
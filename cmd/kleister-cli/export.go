@@ -0,0 +1,437 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+var (
+	// memoryPattern matches memory definitions like 2048, 2048M or 2G.
+	memoryPattern = regexp.MustCompile(`^(?i)\s*([0-9]+)\s*(m|mb|g|gb)?\s*$`)
+)
+
+// exportFormats maps the supported export formats to their writers.
+var exportFormats = map[string]func(*ExportSource, *zip.Writer) error{
+	"multimc":    writeMultiMC,
+	"curseforge": writeCurseForge,
+}
+
+// ExportSource represents a build with all downloaded version files.
+type ExportSource struct {
+	Pack      *kleister.Pack
+	Build     *kleister.Build
+	Minecraft *kleister.Minecraft
+	Forge     *kleister.Forge
+	Files     []*BuildFile
+	Projects  CurseMapping
+
+	// dir is the temporary directory storing the version files.
+	dir string
+}
+
+// LoadExportSource fetches a build with its loader and downloads the version
+// files used on the side into a temporary directory, it gets removed by
+// Close. An empty side includes all version files.
+func LoadExportSource(client kleister.ClientAPI, pack, id, side string) (*ExportSource, error) {
+	record, err := client.PackGet(
+		pack,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	build, err := client.BuildGet(
+		record.Slug,
+		id,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	minecraft, forge, err := ResolveLoader(
+		client,
+		build,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ListBuildFiles(
+		client,
+		NewModResolver(client),
+		record.Slug,
+		build.Slug,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "export")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory")
+	}
+
+	result := &ExportSource{
+		Pack:      record,
+		Build:     build,
+		Minecraft: minecraft,
+		Forge:     forge,
+		Files:     make([]*BuildFile, 0, len(files)),
+		Projects:  make(CurseMapping),
+		dir:       dir,
+	}
+
+	for _, file := range files {
+		if !SupportsSide(file.Mod, side) {
+			continue
+		}
+
+		result.Files = append(result.Files, file)
+
+		file.Local = filepath.Join(dir, filepath.FromSlash(file.Path()))
+
+		_, _, err := FetchVersionFile(
+			file.Version,
+			file.Local,
+		)
+
+		if err != nil {
+			result.Close()
+			return nil, fmt.Errorf("failed to download %s@%s. %s", file.Mod.Slug, file.Version.Slug, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Close removes the downloaded version files.
+func (s *ExportSource) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// ExportBuildArchive writes the build as zip archive in the requested format.
+func ExportBuildArchive(source *ExportSource, format, out string) error {
+	writer, ok := exportFormats[strings.ToLower(format)]
+
+	if !ok {
+		return fmt.Errorf("invalid export format, can be %s", strings.Join(exportFormatNames(), ", "))
+	}
+
+	file, err := os.Create(out)
+
+	if err != nil {
+		return fmt.Errorf("failed to create %s", out)
+	}
+
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	if err := writer(source, archive); err != nil {
+		archive.Close()
+		os.Remove(out)
+
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		os.Remove(out)
+		return fmt.Errorf("failed to write %s", out)
+	}
+
+	return nil
+}
+
+// exportFormatNames returns the sorted names of the export formats.
+func exportFormatNames() []string {
+	result := make([]string, 0, len(exportFormats))
+
+	for name := range exportFormats {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// ResolveLoader returns the Minecraft and Forge records of a build, they are
+// fetched if the build only references them by id or the embedded records
+// don't match the ids.
+func ResolveLoader(client kleister.ClientAPI, build *kleister.Build) (*kleister.Minecraft, *kleister.Forge, error) {
+	minecraft := build.Minecraft
+
+	if minecraft != nil && build.MinecraftID.Valid && minecraft.ID != build.MinecraftID.Int64 {
+		minecraft = nil
+	}
+
+	if minecraft == nil && build.MinecraftID.Valid {
+		related, err := client.MinecraftGet(
+			strconv.FormatInt(build.MinecraftID.Int64, 10),
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		minecraft = related
+	}
+
+	forge := build.Forge
+
+	if forge != nil && build.ForgeID.Valid && forge.ID != build.ForgeID.Int64 {
+		forge = nil
+	}
+
+	if forge == nil && build.ForgeID.Valid {
+		related, err := client.ForgeGet(
+			strconv.FormatInt(build.ForgeID.Int64, 10),
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		forge = related
+	}
+
+	return minecraft, forge, nil
+}
+
+// ForgeVersion returns the Forge version without the Minecraft version, some
+// Forge releases got the Minecraft version prepended or appended.
+func ForgeVersion(forge *kleister.Forge) string {
+	result := forge.Version
+
+	if forge.Minecraft != "" {
+		result = strings.TrimPrefix(result, forge.Minecraft+"-")
+		result = strings.TrimSuffix(result, "-"+forge.Minecraft)
+	}
+
+	return result
+}
+
+// MemoryMegabytes parses a memory definition like 2048, 2048M or 2G into
+// megabytes.
+func MemoryMegabytes(value string) (int64, error) {
+	matches := memoryPattern.FindStringSubmatch(value)
+
+	if matches == nil {
+		return 0, fmt.Errorf("invalid memory definition %q", value)
+	}
+
+	result, err := strconv.ParseInt(matches[1], 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory definition %q", value)
+	}
+
+	switch strings.ToLower(matches[2]) {
+	case "g", "gb":
+		result = result * 1024
+	}
+
+	return result, nil
+}
+
+// writeMultiMC writes the build as MultiMC instance with an instance.cfg, a
+// mmc-pack.json and the version files within .minecraft.
+func writeMultiMC(source *ExportSource, archive *zip.Writer) error {
+	settings := []string{
+		"InstanceType=OneSix",
+		fmt.Sprintf("name=%s", SingleLine(source.Pack.Name+" "+source.Build.Name)),
+		"iconKey=default",
+	}
+
+	if source.Build.MinMemory != "" {
+		memory, err := MemoryMegabytes(source.Build.MinMemory)
+
+		if err != nil {
+			return err
+		}
+
+		// The maximum uses the same value, otherwise the default maximum of
+		// the launcher could be lower than the required minimum.
+		settings = append(
+			settings,
+			"OverrideMemory=true",
+			fmt.Sprintf("MinMemAlloc=%d", memory),
+			fmt.Sprintf("MaxMemAlloc=%d", memory),
+		)
+	}
+
+	// MultiMC derives the Java requirement from the Minecraft version, there
+	// is no instance setting for it, so it can only be noted for the user.
+	if source.Build.MinJava != "" {
+		settings = append(
+			settings,
+			fmt.Sprintf("notes=Requires Java %s or newer", SingleLine(source.Build.MinJava)),
+		)
+
+		fmt.Fprintf(os.Stderr, "warning: MultiMC can't enforce java %s, it's only added to the instance notes\n", source.Build.MinJava)
+	}
+
+	if err := addArchiveFile(archive, "instance.cfg", []byte(strings.Join(settings, "\n")+"\n")); err != nil {
+		return err
+	}
+
+	type component struct {
+		UID       string `json:"uid"`
+		Version   string `json:"version"`
+		Important bool   `json:"important,omitempty"`
+	}
+
+	components := make([]*component, 0)
+
+	if source.Minecraft != nil {
+		components = append(components, &component{
+			UID:       "net.minecraft",
+			Version:   source.Minecraft.Version,
+			Important: true,
+		})
+	}
+
+	if source.Forge != nil {
+		components = append(components, &component{
+			UID:     "net.minecraftforge",
+			Version: ForgeVersion(source.Forge),
+		})
+	}
+
+	content, err := json.MarshalIndent(map[string]interface{}{
+		"formatVersion": 1,
+		"components":    components,
+	}, "", "  ")
+
+	if err != nil {
+		return fmt.Errorf("failed to encode mmc-pack.json. %s", err)
+	}
+
+	if err := addArchiveFile(archive, "mmc-pack.json", append(content, '\n')); err != nil {
+		return err
+	}
+
+	for _, file := range source.Files {
+		if err := addVersionFile(archive, ".minecraft", file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addVersionFile adds a version file below the prefix of the archive.
+func addVersionFile(archive *zip.Writer, prefix string, file *BuildFile) error {
+	return walkVersionFile(file, func(name string, content []byte) error {
+		return addArchiveFile(archive, path.Join(prefix, name), content)
+	})
+}
+
+// walkVersionFile calls the function for every file provided by a version.
+// Plain mod jars are placed within the mods folder, bundles already
+// containing a mods folder provide all their files.
+func walkVersionFile(file *BuildFile, fn func(string, []byte) error) error {
+	bundle, err := zip.OpenReader(file.Local)
+
+	if err == nil {
+		defer bundle.Close()
+
+		if isModBundle(bundle.File) {
+			for _, entry := range bundle.File {
+				if entry.FileInfo().IsDir() {
+					continue
+				}
+
+				name, err := bundleEntryName(entry.Name)
+
+				if err != nil {
+					return err
+				}
+
+				content, err := readZipFile(entry)
+
+				if err != nil {
+					return err
+				}
+
+				if err := fn(name, content); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	content, err := ioutil.ReadFile(file.Local)
+
+	if err != nil {
+		return fmt.Errorf("failed to read %s", file.Local)
+	}
+
+	return fn(
+		file.JarPath(),
+		content,
+	)
+}
+
+// bundleEntryName cleans the name of a bundle entry, it refuses absolute paths
+// and paths pointing outside of the bundle.
+func bundleEntryName(name string) (string, error) {
+	result := path.Clean(strings.Replace(name, "\\", "/", -1))
+
+	if path.IsAbs(result) || result == ".." || strings.HasPrefix(result, "../") {
+		return "", fmt.Errorf("invalid path %s", name)
+	}
+
+	return result, nil
+}
+
+// isModBundle checks if an archive is a bundle with a mods folder instead of
+// a mod jar.
+func isModBundle(files []*zip.File) bool {
+	bundle := false
+
+	for _, file := range files {
+		switch {
+		case strings.HasSuffix(file.Name, ".class"):
+			return false
+		case file.Name == "META-INF/mods.toml", file.Name == "mcmod.info", file.Name == "fabric.mod.json":
+			return false
+		case strings.HasPrefix(file.Name, "mods/"):
+			bundle = true
+		}
+	}
+
+	return bundle
+}
+
+// addArchiveFile adds a file with the content to the archive.
+func addArchiveFile(archive *zip.Writer, name string, content []byte) error {
+	writer, err := archive.Create(name)
+
+	if err != nil {
+		return fmt.Errorf("failed to add %s", name)
+	}
+
+	if _, err := writer.Write(content); err != nil {
+		return fmt.Errorf("failed to add %s", name)
+	}
+
+	return nil
+}
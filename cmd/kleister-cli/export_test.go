@@ -0,0 +1,188 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestMemoryMegabytes(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+		fail  bool
+	}{
+		{"2048", 2048, false},
+		{"2048M", 2048, false},
+		{"2048mb", 2048, false},
+		{"2G", 2048, false},
+		{" 4 GB ", 4096, false},
+		{"1.5G", 0, true},
+		{"2T", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := MemoryMegabytes(tt.value)
+
+		if tt.fail {
+			if err == nil {
+				t.Errorf("MemoryMegabytes(%q): expected an error", tt.value)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("MemoryMegabytes(%q): %s", tt.value, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("MemoryMegabytes(%q): expected %d, got %d", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestWriteMultiMC(t *testing.T) {
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+
+	source := &ExportSource{
+		Pack: &kleister.Pack{
+			Name: "Demo\niconKey=evil",
+		},
+		Build: &kleister.Build{
+			Name:      "1.0.0",
+			MinJava:   "1.8",
+			MinMemory: "3G",
+		},
+		Minecraft: &kleister.Minecraft{
+			Version: "1.12.2",
+		},
+	}
+
+	if err := writeMultiMC(source, archive); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := ""
+
+	for _, file := range reader.File {
+		if file.Name != "instance.cfg" {
+			continue
+		}
+
+		handle, err := file.Open()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, _ := ioutil.ReadAll(handle)
+		handle.Close()
+
+		settings = string(content)
+	}
+
+	for _, line := range []string{
+		"name=Demo iconKey=evil 1.0.0",
+		"OverrideMemory=true",
+		"MinMemAlloc=3072",
+		"MaxMemAlloc=3072",
+		"notes=Requires Java 1.8 or newer",
+	} {
+		if !strings.Contains(settings, line+"\n") {
+			t.Errorf("expected %q within instance.cfg, got %q", line, settings)
+		}
+	}
+
+	if strings.Contains(settings, "\niconKey=evil") {
+		t.Errorf("expected the name on a single line, got %q", settings)
+	}
+}
+
+func TestWalkVersionFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  []string
+		err   bool
+	}{
+		{
+			name:  "plain jar",
+			files: map[string][]byte{"mcmod.info": []byte("[]"), "JEI.class": []byte("class")},
+			want:  []string{"mods/jei-4.16.0.jar"},
+		},
+		{
+			name:  "bundle",
+			files: map[string][]byte{"mods/jei.jar": []byte("jar"), "./config/jei.cfg": []byte("cfg")},
+			want:  []string{"config/jei.cfg", "mods/jei.jar"},
+		},
+		{
+			name:  "parent directory",
+			files: map[string][]byte{"mods/jei.jar": []byte("jar"), "mods/../../evil.sh": []byte("evil")},
+			err:   true,
+		},
+		{
+			name:  "absolute path",
+			files: map[string][]byte{"mods/jei.jar": []byte("jar"), "/etc/cron.d/evil": []byte("evil")},
+			err:   true,
+		},
+		{
+			name:  "backslashes",
+			files: map[string][]byte{"mods/jei.jar": []byte("jar"), "..\\evil.bat": []byte("evil")},
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		file := &BuildFile{
+			Mod:     &kleister.Mod{Slug: "jei"},
+			Version: &kleister.Version{Slug: "4.16.0"},
+			Local:   testJar(t, tt.files),
+		}
+
+		got := []string{}
+
+		err := walkVersionFile(file, func(name string, content []byte) error {
+			got = append(got, name)
+			return nil
+		})
+
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", tt.name, got)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.name, err)
+			continue
+		}
+
+		sort.Strings(got)
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Knetic/govaluate"
+	"gopkg.in/guregu/null.v3"
+	"gopkg.in/urfave/cli.v2"
+)
+
+// FilterRecords keeps only the rows matching the filter expression, an empty
+// expression keeps all rows.
+func FilterRecords(rows reflect.Value, filter string) (reflect.Value, error) {
+	if filter == "" {
+		return rows, nil
+	}
+
+	expression, err := govaluate.NewEvaluableExpression(
+		filter,
+	)
+
+	if err != nil {
+		return rows, fmt.Errorf("failed to parse filter. %s", err)
+	}
+
+	result := reflect.MakeSlice(rows.Type(), 0, rows.Len())
+
+	for i := 0; i < rows.Len(); i++ {
+		match, err := expression.Evaluate(
+			FilterParams(rows.Index(i)),
+		)
+
+		if err != nil {
+			return rows, fmt.Errorf("failed to evaluate filter. %s", err)
+		}
+
+		if val, ok := match.(bool); ok && val {
+			result = reflect.Append(result, rows.Index(i))
+		}
+	}
+
+	return result, nil
+}
+
+// LimitRecords reduces the rows to the first or last record if requested.
+func LimitRecords(c *cli.Context, rows reflect.Value) (reflect.Value, error) {
+	if c.Bool("first") && c.Bool("last") {
+		return rows, fmt.Errorf("conflict, you can only use first or last at once")
+	}
+
+	if rows.Len() == 0 {
+		return rows, nil
+	}
+
+	if c.Bool("first") {
+		return rows.Slice(0, 1), nil
+	}
+
+	if c.Bool("last") {
+		return rows.Slice(rows.Len()-1, rows.Len()), nil
+	}
+
+	return rows, nil
+}
+
+// FilterParams derives the filter parameters from the scalar fields of a
+// record. Fields of related records are available with their path like
+// [User.Slug], missing related records provide zero values to not break the
+// expression. Numbers and times are provided as float64 to be comparable
+// within expressions.
+func FilterParams(record reflect.Value) map[string]interface{} {
+	result := make(map[string]interface{})
+	record = reflect.Indirect(record)
+
+	if record.Kind() != reflect.Struct {
+		return result
+	}
+
+	for i := 0; i < record.NumField(); i++ {
+		field := record.Type().Field(i)
+
+		if !outputVisible(field) {
+			continue
+		}
+
+		if outputScalar(field.Type) {
+			result[field.Name] = filterValue(record.Field(i))
+			continue
+		}
+
+		if field.Type.Kind() != reflect.Ptr || field.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		related := reflect.Zero(field.Type.Elem())
+
+		if !record.Field(i).IsNil() {
+			related = record.Field(i).Elem()
+		}
+
+		for j := 0; j < related.NumField(); j++ {
+			nested := related.Type().Field(j)
+
+			if outputVisible(nested) && outputScalar(nested.Type) {
+				result[field.Name+"."+nested.Name] = filterValue(related.Field(j))
+			}
+		}
+	}
+
+	return result
+}
+
+// filterValue converts a field value to a type supported by govaluate.
+func filterValue(value reflect.Value) interface{} {
+	switch val := value.Interface().(type) {
+	case time.Time:
+		return float64(val.Unix())
+	case null.Int:
+		if !val.Valid {
+			return nil
+		}
+
+		return float64(val.Int64)
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int64:
+		return float64(value.Int())
+	}
+
+	return value.Interface()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+var filterRecords = []*kleister.UserPack{
+	{User: &kleister.User{ID: 1, Slug: "admin", Admin: true}, Pack: &kleister.Pack{ID: 2, Slug: "demo"}, Perm: "owner"},
+	{User: &kleister.User{ID: 3, Slug: "guest"}, Perm: "user"},
+	{Pack: &kleister.Pack{ID: 4, Slug: "other"}, Perm: "user"},
+}
+
+func TestFilterParams(t *testing.T) {
+	updated := time.Date(2019, 5, 7, 8, 0, 0, 0, time.UTC)
+	got := FilterParams(reflect.ValueOf(&kleister.UserPack{
+		User: &kleister.User{ID: 1, Slug: "admin", UpdatedAt: updated},
+		Perm: "owner",
+	}))
+
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{"Perm", "owner"},
+		{"User.ID", float64(1)},
+		{"User.Slug", "admin"},
+		{"User.UpdatedAt", float64(updated.Unix())},
+		{"Pack.ID", float64(0)},
+		{"Pack.Slug", ""},
+	}
+
+	for _, tt := range tests {
+		if val, ok := got[tt.key]; !ok || val != tt.want {
+			t.Errorf("%s: expected %#v, got %#v", tt.key, tt.want, val)
+		}
+	}
+
+	if _, ok := got["User.Password"]; ok {
+		t.Errorf("expected User.Password to be hidden")
+	}
+
+	if _, ok := got["User"]; ok {
+		t.Errorf("expected no param for the related record itself")
+	}
+}
+
+func TestFilterRecords(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"owner", "user", "user"}},
+		{"Perm == 'user'", []string{"user", "user"}},
+		{"[User.Slug] == 'admin'", []string{"owner"}},
+		{"[Pack.Slug] == 'other'", []string{"user"}},
+		{"[User.ID] > 2", []string{"user"}},
+		{"[User.Admin]", []string{"owner"}},
+	}
+
+	for _, tt := range tests {
+		rows, err := FilterRecords(reflect.ValueOf(filterRecords), tt.filter)
+
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", tt.filter, err)
+			continue
+		}
+
+		got := []string{}
+
+		for _, record := range rows.Interface().([]*kleister.UserPack) {
+			got = append(got, record.Perm)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.filter, tt.want, got)
+		}
+	}
+
+	if _, err := FilterRecords(reflect.ValueOf(filterRecords), "Perm =="); err == nil {
+		t.Errorf("expected an error for an invalid filter")
+	}
+}
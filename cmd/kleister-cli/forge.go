@@ -1,15 +1,11 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
-	"text/template"
 
-	"github.com/Knetic/govaluate"
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
 )
@@ -45,16 +41,16 @@ func Forge() *cli.Command {
 						Value: "Slug",
 						Usage: "sort by this field",
 					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "Sort by a field, prefix with a dash for descending order",
+					},
 					&cli.StringFlag{
 						Name:  "format",
 						Value: tmplForgeList,
 						Usage: "custom output format",
 					},
-					&cli.StringFlag{
-						Name:  "output",
-						Value: "text",
-						Usage: "output as format, json or xml",
-					},
 					&cli.StringFlag{
 						Name:  "filter",
 						Value: "",
@@ -70,6 +66,11 @@ func Forge() *cli.Command {
 						Value: false,
 						Usage: "return only last record",
 					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "comma separated list of columns for table and csv output",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, ForgeList)
@@ -105,9 +106,29 @@ func Forge() *cli.Command {
 								Usage: "custom output format",
 							},
 							&cli.StringFlag{
-								Name:  "output",
-								Value: "text",
-								Usage: "output as format, json or xml",
+								Name:  "columns",
+								Value: "",
+								Usage: "comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "filter by expression on the record fields",
+							},
+							&cli.BoolFlag{
+								Name:  "first",
+								Value: false,
+								Usage: "return only first record",
+							},
+							&cli.BoolFlag{
+								Name:  "last",
+								Value: false,
+								Usage: "return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -173,140 +194,36 @@ func Forge() *cli.Command {
 
 // ForgeList provides the sub-command to list all Forge versions.
 func ForgeList(c *cli.Context, client kleister.ClientAPI) error {
-	var (
-		result []*kleister.Forge
-	)
-
 	records, err := client.ForgeList()
 
 	if err != nil {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.IsSet("first") && c.IsSet("last") {
-		return fmt.Errorf("conflict, you can only use first or last at once")
-	}
-
-	if c.IsSet("filter") {
-		expression, err := govaluate.NewEvaluableExpression(
-			c.String("filter"),
-		)
-
-		if err != nil {
-			return fmt.Errorf("failed to parse filter. %s", err)
-		}
-
-		for _, record := range records {
-			params := make(map[string]interface{}, 3)
-			params["Slug"] = record.Slug
-			params["Version"] = record.Version
-			params["Minecraft"] = record.Minecraft
-
-			match, err := expression.Evaluate(
-				params,
-			)
-
-			if err != nil {
-				return fmt.Errorf("failed to evaluate filter. %s", err)
-			}
-
-			if match.(bool) {
-				result = append(result, record)
-			}
-		}
-	} else {
-		result = records
-	}
-
 	switch strings.ToLower(c.String("sort")) {
 	case "slug":
 		sort.Sort(
 			kleister.ForgeBySlug(
-				result,
+				records,
 			),
 		)
 	case "version":
 		sort.Sort(
 			kleister.ForgeByVersion(
-				result,
+				records,
 			),
 		)
 	case "minecraft":
 		sort.Sort(
 			kleister.ForgeByMinecraft(
-				result,
+				records,
 			),
 		)
 	default:
 		return fmt.Errorf("the sort value %s is invalid, can be slug, version or minecraft", c.String("sort"))
 	}
 
-	if c.Bool("first") {
-		result = []*kleister.Forge{
-			result[0],
-		}
-	}
-
-	if c.Bool("last") {
-		result = []*kleister.Forge{
-			result[len(result)-1],
-		}
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(result, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(result, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(result) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range result {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // ForgeRefresh provides the sub-command to refresh the Forge versions.
@@ -333,60 +250,7 @@ func ForgeBuildList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // ForgeBuildAppend provides the sub-command to append a build to the Forge.
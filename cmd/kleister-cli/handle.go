@@ -1,10 +1,14 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
 
+	"github.com/jackspirou/syscerts"
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
 )
@@ -12,15 +16,152 @@ import (
 // HandleFunc is the real handle implementation.
 type HandleFunc func(c *cli.Context, client kleister.ClientAPI) error
 
-// Handle wraps the command function handler.
+// Handle wraps the command function handler. Rejected tokens get refreshed
+// by the transport and only the rejected request is retried, the command
+// itself is never executed twice.
 func Handle(c *cli.Context, fn HandleFunc) error {
+	server, token, context := ResolveCredentials(c)
+	client, transport := newClient(server, token)
+
+	transport.refresh = func() string {
+		return RefreshToken(server, context)
+	}
+
+	err := fn(c, client)
+
+	if err != nil && transport.unauthorized {
+		fmt.Fprintf(os.Stderr, "error: authentication failed, please run login.\n")
+		os.Exit(3)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+// RefreshToken fetches a new token if credentials are available within the
+// KLEISTER_USERNAME and KLEISTER_PASSWORD environment variables, it gets
+// stored within the context if the previous token was taken from there.
+func RefreshToken(server string, context *ConfigContext) string {
+	username := os.Getenv("KLEISTER_USERNAME")
+	password := os.Getenv("KLEISTER_PASSWORD")
+
+	if username == "" || password == "" {
+		return ""
+	}
+
+	login, err := NewClient(server, "").AuthLogin(
+		username,
+		password,
+	)
+
+	if err != nil {
+		return ""
+	}
+
+	token, err := NewClient(server, login.Token).ProfileToken()
+
+	if err != nil {
+		return ""
+	}
+
+	if context != nil {
+		config, err := LoadConfig()
+
+		if err != nil {
+			return token.Token
+		}
+
+		if record := config.Get(context.Name); record != nil {
+			record.Token = token.Token
+
+			if err := config.Save(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to store refreshed token. %s\n", err)
+			}
+		}
+	}
+
+	return token.Token
+}
+
+// ResolveCredentials determines the server and token to use. Explicitly set
+// flags or environment variables take precedence over the selected context,
+// the current context only applies if neither server nor token have been set.
+// The returned context is only defined if the token has been taken from it.
+func ResolveCredentials(c *cli.Context) (string, string, *ConfigContext) {
 	var (
 		server = c.String("server")
 		token  = c.String("token")
 
-		client kleister.ClientAPI
+		context *ConfigContext
 	)
 
+	config, err := LoadConfig()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if name := c.String("context"); name != "" {
+		context = config.Get(name)
+
+		if context == nil {
+			fmt.Fprintf(os.Stderr, "error: context %s doesn't exist.\n", name)
+			os.Exit(1)
+		}
+	} else if !c.IsSet("server") && !c.IsSet("token") {
+		context = config.Active()
+	}
+
+	if context == nil {
+		return server, token, nil
+	}
+
+	if !c.IsSet("server") {
+		server = context.Server
+	}
+
+	if c.IsSet("token") {
+		return server, token, nil
+	}
+
+	return server, context.Token, context
+}
+
+// ResolveContext returns the server and token of the context if the value
+// matches a context name, otherwise the value is treated as server address.
+func ResolveContext(value, token string) (string, string) {
+	config, err := LoadConfig()
+
+	if err != nil {
+		return value, token
+	}
+
+	if context := config.Get(value); context != nil {
+		if token == "" {
+			token = context.Token
+		}
+
+		return context.Server, token
+	}
+
+	return value, token
+}
+
+// NewClient validates the server address and creates an API client, which is
+// authenticated if a token is provided.
+func NewClient(server, token string) kleister.ClientAPI {
+	client, _ := newClient(server, token)
+	return client
+}
+
+// newClient creates an API client and returns the transport to inspect
+// rejected authentications.
+func newClient(server, token string) (kleister.ClientAPI, *authTransport) {
 	if server == "" {
 		fmt.Fprintf(os.Stderr, "error: you must provide the server address.\n")
 		os.Exit(1)
@@ -31,6 +172,13 @@ func Handle(c *cli.Context, fn HandleFunc) error {
 		os.Exit(1)
 	}
 
+	transport := &authTransport{
+		token: token,
+		base:  defaultTransport(),
+	}
+
+	var client kleister.ClientAPI
+
 	if token == "" {
 		client = kleister.NewClient(
 			server,
@@ -42,10 +190,85 @@ func Handle(c *cli.Context, fn HandleFunc) error {
 		)
 	}
 
-	if err := fn(c, client); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
-		os.Exit(2)
+	client.SetClient(
+		&http.Client{
+			Transport: transport,
+		},
+	)
+
+	return client, transport
+}
+
+// defaultTransport returns the same transport the SDK uses for authenticated
+// clients, it respects proxies and loads the system certificates.
+func defaultTransport() http.RoundTripper {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			RootCAs: syscerts.SystemRootsPool(),
+		},
 	}
+}
 
-	return nil
+// authTransport authenticates requests with the token. If the server rejects
+// the token it gets refreshed once and the rejected request is retried, if
+// that fails as well the rejection is recorded.
+type authTransport struct {
+	token        string
+	base         http.RoundTripper
+	refresh      func() string
+	unauthorized bool
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.send(req, req.Body)
+
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if t.refresh != nil && (req.Body == nil || req.GetBody != nil) {
+		refresh := t.refresh
+		t.refresh = nil
+
+		if token := refresh(); token != "" {
+			body := req.Body
+
+			if req.GetBody != nil {
+				if body, err = req.GetBody(); err != nil {
+					return resp, nil
+				}
+			}
+
+			resp.Body.Close()
+			t.token = token
+
+			if resp, err = t.send(req, body); err != nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+		}
+	}
+
+	t.unauthorized = true
+	return resp, nil
+}
+
+// send executes the request with the current token and body.
+func (t *authTransport) send(req *http.Request, body io.ReadCloser) (*http.Response, error) {
+	clone := new(http.Request)
+	*clone = *req
+
+	clone.Body = body
+	clone.Header = make(http.Header, len(req.Header))
+
+	for key, values := range req.Header {
+		clone.Header[key] = append([]string(nil), values...)
+	}
+
+	if t.token != "" {
+		clone.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	return t.base.RoundTrip(clone)
 }
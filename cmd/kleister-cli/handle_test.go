@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthTransportRefresh(t *testing.T) {
+	tests := []struct {
+		name         string
+		refreshed    string
+		requests     int
+		refreshes    int
+		status       int
+		unauthorized bool
+	}{
+		{"refreshed", "fresh", 2, 1, http.StatusOK, false},
+		{"rejected", "", 1, 1, http.StatusUnauthorized, true},
+		{"stale", "other", 2, 1, http.StatusUnauthorized, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if body, _ := ioutil.ReadAll(r.Body); string(body) != "payload" {
+					t.Errorf("expected body payload, got %q", body)
+				}
+
+				if r.Header.Get("Authorization") != "Bearer fresh" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			defer server.Close()
+
+			refreshes := 0
+
+			transport := &authTransport{
+				token: "expired",
+				base:  http.DefaultTransport,
+				refresh: func() string {
+					refreshes++
+					return tt.refreshed
+				},
+			}
+
+			client := &http.Client{
+				Transport: transport,
+			}
+
+			for i := 0; i < 2; i++ {
+				resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				resp.Body.Close()
+
+				if i == 0 && resp.StatusCode != tt.status {
+					t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+				}
+
+				if i == 0 && requests != tt.requests {
+					t.Errorf("expected %d requests, got %d", tt.requests, requests)
+				}
+			}
+
+			if refreshes != tt.refreshes {
+				t.Errorf("expected %d refreshes, got %d", tt.refreshes, refreshes)
+			}
+
+			if transport.unauthorized != tt.unauthorized {
+				t.Errorf("expected unauthorized %v, got %v", tt.unauthorized, transport.unauthorized)
+			}
+		})
+	}
+}
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	// slugInvalid matches all characters not allowed within slugs.
+	slugInvalid = regexp.MustCompile(`[^a-z0-9\-_.]+`)
+)
+
+// JarMetadata represents the metadata of a mod defined within a jar file.
+type JarMetadata struct {
+	ID          string
+	Name        string
+	Version     string
+	Description string
+	Author      string
+	Website     string
+	Side        string
+
+	// Provides lists further mod ids defined by the same jar.
+	Provides []string
+
+	// Dependencies lists the declared dependencies and incompatibilities.
+	Dependencies []*JarDependency
+}
+
+// JarDependency represents a dependency or incompatibility declared by a mod.
+// The type is one of required, optional, incompatible or discouraged.
+type JarDependency struct {
+	ModID string
+	Range string
+	Type  string
+
+	// fabric marks ranges using the semver predicates of Fabric.
+	fabric bool
+}
+
+// Slug returns the slug for the mod derived from the mod id.
+func (m *JarMetadata) Slug() string {
+	return Slugify(m.ID)
+}
+
+// Slugify converts a value into a valid slug.
+func Slugify(value string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
+
+// mcmodInfo represents a single mod within a mcmod.info file.
+type mcmodInfo struct {
+	ModID        string   `json:"modid"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Version      string   `json:"version"`
+	URL          string   `json:"url"`
+	AuthorList   []string `json:"authorList"`
+	Authors      []string `json:"authors"`
+	RequiredMods []string `json:"requiredMods"`
+}
+
+// mcmodInfoList represents the second version of the mcmod.info format.
+type mcmodInfoList struct {
+	ModList []*mcmodInfo `json:"modList"`
+}
+
+// modsToml represents the META-INF/mods.toml file of Forge mods.
+type modsToml struct {
+	DisplayURL string `toml:"displayURL"`
+	Authors    string `toml:"authors"`
+	Mods       []struct {
+		ModID       string `toml:"modId"`
+		Version     string `toml:"version"`
+		DisplayName string `toml:"displayName"`
+		DisplayURL  string `toml:"displayURL"`
+		Description string `toml:"description"`
+		Authors     string `toml:"authors"`
+	} `toml:"mods"`
+	Dependencies map[string][]struct {
+		ModID        string `toml:"modId"`
+		Mandatory    *bool  `toml:"mandatory"`
+		Type         string `toml:"type"`
+		VersionRange string `toml:"versionRange"`
+	} `toml:"dependencies"`
+}
+
+// fabricModJSON represents the fabric.mod.json file of Fabric mods.
+type fabricModJSON struct {
+	ID          string                     `json:"id"`
+	Version     string                     `json:"version"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Authors     []json.RawMessage          `json:"authors"`
+	Contact     map[string]string          `json:"contact"`
+	Environment string                     `json:"environment"`
+	Provides    []string                   `json:"provides"`
+	Depends     map[string]json.RawMessage `json:"depends"`
+	Breaks      map[string]json.RawMessage `json:"breaks"`
+	Conflicts   map[string]json.RawMessage `json:"conflicts"`
+}
+
+// ReadJarMetadata reads the mod metadata of a jar file, it supports
+// META-INF/mods.toml, mcmod.info and fabric.mod.json.
+func ReadJarMetadata(path string) (*JarMetadata, error) {
+	archive, err := zip.OpenReader(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s as jar", path)
+	}
+
+	defer archive.Close()
+
+	return readJarMetadata(archive.File, path)
+}
+
+// ReadModMetadata reads the mod metadata of a version file, it supports plain
+// jars and bundles containing jars within a mods folder.
+func ReadModMetadata(path string) ([]*JarMetadata, error) {
+	archive, err := zip.OpenReader(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s as jar", path)
+	}
+
+	defer archive.Close()
+
+	if !isModBundle(archive.File) {
+		result, err := readJarMetadata(archive.File, path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*JarMetadata{result}, nil
+	}
+
+	result := make([]*JarMetadata, 0)
+
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, "mods/") || !strings.HasSuffix(file.Name, ".jar") {
+			continue
+		}
+
+		content, err := readZipFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		nested, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s within %s as jar", file.Name, path)
+		}
+
+		metadata, err := readJarMetadata(nested.File, path+"!"+file.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, metadata)
+	}
+
+	return result, nil
+}
+
+// readJarMetadata reads the mod metadata from the files of a jar. Versions
+// with unresolved placeholders like ${file.jarVersion} are returned empty.
+func readJarMetadata(entries []*zip.File, path string) (*JarMetadata, error) {
+	files := make(map[string]*zip.File, len(entries))
+
+	for _, file := range entries {
+		files[file.Name] = file
+	}
+
+	var (
+		result *JarMetadata
+	)
+
+	if file, ok := files["META-INF/mods.toml"]; ok {
+		content, err := readZipFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = parseModsToml(content)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mods.toml of %s. %s", path, err)
+		}
+
+		if strings.Contains(result.Version, "${file.jarVersion}") {
+			version := ""
+
+			if manifest, ok := files["META-INF/MANIFEST.MF"]; ok {
+				content, err := readZipFile(manifest)
+
+				if err != nil {
+					return nil, err
+				}
+
+				version = manifestAttribute(content, "Implementation-Version")
+			}
+
+			if version == "" {
+				result.Version = ""
+			} else {
+				result.Version = strings.Replace(
+					result.Version,
+					"${file.jarVersion}",
+					version,
+					-1,
+				)
+			}
+		}
+	} else if file, ok := files["mcmod.info"]; ok {
+		content, err := readZipFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = parseMcmodInfo(content)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mcmod.info of %s. %s", path, err)
+		}
+	} else if file, ok := files["fabric.mod.json"]; ok {
+		content, err := readZipFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = parseFabricModJSON(content)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse fabric.mod.json of %s. %s", path, err)
+		}
+	} else {
+		return nil, fmt.Errorf("failed to find mod metadata within %s", path)
+	}
+
+	if strings.Contains(result.Version, "${") {
+		result.Version = ""
+	}
+
+	return result, nil
+}
+
+// parseModsToml parses the first mod defined within a mods.toml file.
+func parseModsToml(content []byte) (*JarMetadata, error) {
+	definition := &modsToml{}
+
+	if _, err := toml.Decode(string(content), definition); err != nil {
+		return nil, err
+	}
+
+	if len(definition.Mods) == 0 {
+		return nil, fmt.Errorf("no mod defined")
+	}
+
+	mod := definition.Mods[0]
+
+	result := &JarMetadata{
+		ID:          mod.ModID,
+		Name:        mod.DisplayName,
+		Version:     mod.Version,
+		Description: strings.TrimSpace(mod.Description),
+		Author:      mod.Authors,
+		Website:     mod.DisplayURL,
+		Side:        "both",
+	}
+
+	if result.Author == "" {
+		result.Author = definition.Authors
+	}
+
+	if result.Website == "" {
+		result.Website = definition.DisplayURL
+	}
+
+	for _, other := range definition.Mods[1:] {
+		result.Provides = append(result.Provides, other.ModID)
+	}
+
+	for _, owner := range definition.Mods {
+		for _, dependency := range definition.Dependencies[owner.ModID] {
+			kind := strings.ToLower(dependency.Type)
+
+			if kind == "" {
+				kind = "optional"
+
+				if dependency.Mandatory != nil && *dependency.Mandatory {
+					kind = "required"
+				}
+			}
+
+			result.Dependencies = append(result.Dependencies, &JarDependency{
+				ModID: dependency.ModID,
+				Range: dependency.VersionRange,
+				Type:  kind,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// parseMcmodInfo parses the first mod defined within a mcmod.info file, it
+// supports the plain list and the modList format.
+func parseMcmodInfo(content []byte) (*JarMetadata, error) {
+	mods := []*mcmodInfo{}
+
+	if err := json.Unmarshal(content, &mods); err != nil {
+		list := &mcmodInfoList{}
+
+		if err := json.Unmarshal(content, list); err != nil {
+			return nil, err
+		}
+
+		mods = list.ModList
+	}
+
+	if len(mods) == 0 {
+		return nil, fmt.Errorf("no mod defined")
+	}
+
+	mod := mods[0]
+	authors := mod.AuthorList
+
+	if len(authors) == 0 {
+		authors = mod.Authors
+	}
+
+	result := &JarMetadata{
+		ID:          mod.ModID,
+		Name:        mod.Name,
+		Version:     mod.Version,
+		Description: mod.Description,
+		Author:      strings.Join(authors, ", "),
+		Website:     mod.URL,
+		Side:        "both",
+	}
+
+	for _, other := range mods[1:] {
+		result.Provides = append(result.Provides, other.ModID)
+	}
+
+	for _, other := range mods {
+		for _, required := range other.RequiredMods {
+			parts := strings.SplitN(required, "@", 2)
+
+			dependency := &JarDependency{
+				ModID: strings.TrimSpace(parts[0]),
+				Type:  "required",
+			}
+
+			if len(parts) == 2 {
+				dependency.Range = strings.TrimSpace(parts[1])
+			}
+
+			result.Dependencies = append(result.Dependencies, dependency)
+		}
+	}
+
+	return result, nil
+}
+
+// parseFabricModJSON parses a fabric.mod.json file.
+func parseFabricModJSON(content []byte) (*JarMetadata, error) {
+	mod := &fabricModJSON{}
+
+	if err := json.Unmarshal(content, mod); err != nil {
+		return nil, err
+	}
+
+	authors := []string{}
+
+	for _, raw := range mod.Authors {
+		var name string
+
+		if err := json.Unmarshal(raw, &name); err == nil {
+			authors = append(authors, name)
+			continue
+		}
+
+		person := struct {
+			Name string `json:"name"`
+		}{}
+
+		if err := json.Unmarshal(raw, &person); err == nil && person.Name != "" {
+			authors = append(authors, person.Name)
+		}
+	}
+
+	result := &JarMetadata{
+		ID:          mod.ID,
+		Name:        mod.Name,
+		Version:     mod.Version,
+		Description: mod.Description,
+		Author:      strings.Join(authors, ", "),
+		Website:     mod.Contact["homepage"],
+		Side:        "both",
+	}
+
+	switch mod.Environment {
+	case "client", "server":
+		result.Side = mod.Environment
+	}
+
+	result.Provides = mod.Provides
+
+	for _, group := range []struct {
+		kind    string
+		entries map[string]json.RawMessage
+	}{
+		{"required", mod.Depends},
+		{"incompatible", mod.Breaks},
+		{"discouraged", mod.Conflicts},
+	} {
+		for id, raw := range group.entries {
+			result.Dependencies = append(result.Dependencies, &JarDependency{
+				ModID:  id,
+				Range:  fabricRange(raw),
+				Type:   group.kind,
+				fabric: true,
+			})
+		}
+	}
+
+	sort.Slice(result.Dependencies, func(i, j int) bool {
+		return result.Dependencies[i].ModID < result.Dependencies[j].ModID
+	})
+
+	return result, nil
+}
+
+// fabricRange converts the version predicates of a Fabric dependency into a
+// single range, multiple alternatives are joined by ||.
+func fabricRange(raw json.RawMessage) string {
+	var single string
+
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single
+	}
+
+	multiple := []string{}
+
+	if err := json.Unmarshal(raw, &multiple); err == nil {
+		return strings.Join(multiple, " || ")
+	}
+
+	return "*"
+}
+
+// readZipFile reads the content of a file within a zip archive.
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s", file.Name)
+	}
+
+	defer reader.Close()
+
+	content, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s", file.Name)
+	}
+
+	return content, nil
+}
+
+// manifestAttribute reads an attribute from a jar manifest file.
+func manifestAttribute(content []byte, name string) string {
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == name {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testModsToml = `
+modLoader="javafml"
+loaderVersion="[36,)"
+authors="mezz"
+
+[[mods]]
+modId="jei"
+version="${file.jarVersion}"
+displayName="Just Enough Items"
+displayURL="https://jei.example.com"
+
+[[dependencies.jei]]
+modId="forge"
+mandatory=true
+versionRange="[36.1,)"
+
+[[dependencies.jei]]
+modId="optifine"
+type="incompatible"
+versionRange="*"
+`
+
+const testMcmodInfo = `[{
+  "modid": "ironchest",
+  "name": "Iron Chests",
+  "version": "7.0.72",
+  "url": "https://ironchest.example.com",
+  "authorList": ["cpw", "progwml6"],
+  "requiredMods": ["forge@[14.23.5,)"]
+}, {
+  "modid": "ironchest-api",
+  "version": "7.0.72"
+}]`
+
+const testFabricModJSON = `{
+  "id": "sodium",
+  "version": "0.2.0",
+  "name": "Sodium",
+  "environment": "client",
+  "authors": ["jellysquid3", {"name": "contributor"}],
+  "contact": {"homepage": "https://sodium.example.com"},
+  "depends": {"fabricloader": ">=0.11.3", "minecraft": ["1.16.4", "1.16.5"]},
+  "breaks": {"optifabric": "*"}
+}`
+
+func testZip(t *testing.T, files map[string][]byte) []byte {
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+
+	for name, content := range files {
+		writer, err := archive.Create(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := writer.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func testJar(t *testing.T, files map[string][]byte) string {
+	path := filepath.Join(t.TempDir(), "mod.jar")
+
+	if err := ioutil.WriteFile(path, testZip(t, files), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestReadJarMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  *JarMetadata
+	}{
+		{
+			name: "mods.toml with manifest",
+			files: map[string][]byte{
+				"META-INF/mods.toml":   []byte(testModsToml),
+				"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\r\nImplementation-Version: 7.6.1.65\r\n"),
+			},
+			want: &JarMetadata{
+				ID:      "jei",
+				Name:    "Just Enough Items",
+				Version: "7.6.1.65",
+				Author:  "mezz",
+				Website: "https://jei.example.com",
+				Side:    "both",
+				Dependencies: []*JarDependency{
+					{ModID: "forge", Range: "[36.1,)", Type: "required"},
+					{ModID: "optifine", Range: "*", Type: "incompatible"},
+				},
+			},
+		},
+		{
+			name: "mods.toml without manifest",
+			files: map[string][]byte{
+				"META-INF/mods.toml": []byte(testModsToml),
+			},
+			want: &JarMetadata{
+				ID:      "jei",
+				Name:    "Just Enough Items",
+				Version: "",
+				Author:  "mezz",
+				Website: "https://jei.example.com",
+				Side:    "both",
+				Dependencies: []*JarDependency{
+					{ModID: "forge", Range: "[36.1,)", Type: "required"},
+					{ModID: "optifine", Range: "*", Type: "incompatible"},
+				},
+			},
+		},
+		{
+			name: "mcmod.info",
+			files: map[string][]byte{
+				"mcmod.info": []byte(testMcmodInfo),
+			},
+			want: &JarMetadata{
+				ID:       "ironchest",
+				Name:     "Iron Chests",
+				Version:  "7.0.72",
+				Author:   "cpw, progwml6",
+				Website:  "https://ironchest.example.com",
+				Side:     "both",
+				Provides: []string{"ironchest-api"},
+				Dependencies: []*JarDependency{
+					{ModID: "forge", Range: "[14.23.5,)", Type: "required"},
+				},
+			},
+		},
+		{
+			name: "mcmod.info with placeholder",
+			files: map[string][]byte{
+				"mcmod.info": []byte(`[{"modid": "dev", "version": "${version}"}]`),
+			},
+			want: &JarMetadata{
+				ID:   "dev",
+				Side: "both",
+			},
+		},
+		{
+			name: "fabric.mod.json",
+			files: map[string][]byte{
+				"fabric.mod.json": []byte(testFabricModJSON),
+			},
+			want: &JarMetadata{
+				ID:      "sodium",
+				Name:    "Sodium",
+				Version: "0.2.0",
+				Author:  "jellysquid3, contributor",
+				Website: "https://sodium.example.com",
+				Side:    "client",
+				Dependencies: []*JarDependency{
+					{ModID: "fabricloader", Range: ">=0.11.3", Type: "required", fabric: true},
+					{ModID: "minecraft", Range: "1.16.4 || 1.16.5", Type: "required", fabric: true},
+					{ModID: "optifabric", Range: "*", Type: "incompatible", fabric: true},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadJarMetadata(testJar(t, tt.files))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReadJarMetadataMissing(t *testing.T) {
+	path := testJar(t, map[string][]byte{
+		"README.md": []byte("no metadata"),
+	})
+
+	if _, err := ReadJarMetadata(path); err == nil {
+		t.Errorf("expected an error for a jar without metadata")
+	}
+}
+
+func TestReadModMetadataBundle(t *testing.T) {
+	path := testJar(t, map[string][]byte{
+		"mods/ironchest.jar": testZip(t, map[string][]byte{
+			"mcmod.info": []byte(testMcmodInfo),
+		}),
+		"mods/sodium.jar": testZip(t, map[string][]byte{
+			"fabric.mod.json": []byte(testFabricModJSON),
+		}),
+		"config/ironchest.cfg": []byte("setting=true"),
+	})
+
+	records, err := ReadModMetadata(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, 0, len(records))
+
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+
+	if len(ids) != 2 || !(ids[0] == "ironchest" && ids[1] == "sodium" || ids[0] == "sodium" && ids[1] == "ironchest") {
+		t.Errorf("expected ironchest and sodium, got %v", ids)
+	}
+}
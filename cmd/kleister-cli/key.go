@@ -1,11 +1,8 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
-	"text/template"
 
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
@@ -43,15 +40,30 @@ func Key() *cli.Command {
 						Value: tmplKeyList,
 						Usage: "Custom output format",
 					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "Comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "Sort by a field, prefix with a dash for descending order",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Value: "",
+						Usage: "Filter by expression on the record fields",
+					},
 					&cli.BoolFlag{
-						Name:  "json",
+						Name:  "first",
 						Value: false,
-						Usage: "Print in JSON format",
+						Usage: "Return only first record",
 					},
 					&cli.BoolFlag{
-						Name:  "xml",
+						Name:  "last",
 						Value: false,
-						Usage: "Print in XML format",
+						Usage: "Return only last record",
 					},
 				},
 				Action: func(c *cli.Context) error {
@@ -73,16 +85,6 @@ func Key() *cli.Command {
 						Value: tmplKeyShow,
 						Usage: "Custom output format",
 					},
-					&cli.BoolFlag{
-						Name:  "json",
-						Value: false,
-						Usage: "Print in JSON format",
-					},
-					&cli.BoolFlag{
-						Name:  "xml",
-						Value: false,
-						Usage: "Print in XML format",
-					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, KeyShow)
@@ -171,60 +173,7 @@ func KeyList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // KeyShow provides the sub-command to show key details.
@@ -237,47 +186,7 @@ func KeyShow(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	return tmpl.Execute(os.Stdout, record)
+	return OutputRecord(c, record)
 }
 
 // KeyDelete provides the sub-command to delete a key.
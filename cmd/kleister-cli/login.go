@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"gopkg.in/urfave/cli.v2"
+)
+
+// Login provides the sub-command to sign in and persist a token.
+func Login() *cli.Command {
+	return &cli.Command{
+		Name:      "login",
+		Usage:     "sign in and store the token in the context",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "username",
+				Value:   "",
+				Usage:   "username for authentication, prompted if missing",
+				EnvVars: []string{"KLEISTER_USERNAME"},
+			},
+			&cli.BoolFlag{
+				Name:  "password-stdin",
+				Value: false,
+				Usage: "read the password from stdin",
+			},
+			&cli.StringFlag{
+				Name:  "name",
+				Value: "default",
+				Usage: "name of the context to create if none is selected",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return HandleConfig(c, LoginAction)
+		},
+	}
+}
+
+// Logout provides the sub-command to remove a persisted token.
+func Logout() *cli.Command {
+	return &cli.Command{
+		Name:      "logout",
+		Usage:     "remove the token from the context",
+		ArgsUsage: " ",
+		Action: func(c *cli.Context) error {
+			return HandleConfig(c, LogoutAction)
+		},
+	}
+}
+
+// LoginAction exchanges the credentials for a token and stores it within the
+// selected context, a context gets created if none is selected.
+func LoginAction(c *cli.Context, config *Config) error {
+	context := selectedContext(c, config)
+
+	if context == nil {
+		context = &ConfigContext{
+			Name:   c.String("name"),
+			Server: c.String("server"),
+		}
+
+		if name := c.String("context"); name != "" {
+			context.Name = name
+		}
+	}
+
+	if c.IsSet("server") {
+		context.Server = c.String("server")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	username := c.String("username")
+
+	if username == "" {
+		fmt.Fprintf(os.Stderr, "Username: ")
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			return fmt.Errorf("failed to read username")
+		}
+
+		username = strings.TrimSpace(line)
+	}
+
+	if username == "" {
+		return fmt.Errorf("you must provide a username")
+	}
+
+	password, err := readPassword(reader, c.Bool("password-stdin"))
+
+	if err != nil {
+		return err
+	}
+
+	login, err := NewClient(context.Server, "").AuthLogin(
+		username,
+		password,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	token, err := NewClient(context.Server, login.Token).ProfileToken()
+
+	if err != nil {
+		return err
+	}
+
+	context.Token = token.Token
+	config.Set(context)
+
+	if config.Current == "" {
+		config.Current = context.Name
+	}
+
+	if err := config.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully logged in to context %s\n", context.Name)
+	return nil
+}
+
+// LogoutAction removes the token from the selected context.
+func LogoutAction(c *cli.Context, config *Config) error {
+	context := selectedContext(c, config)
+
+	if context == nil {
+		return fmt.Errorf("no context selected")
+	}
+
+	if context.Token == "" {
+		fmt.Fprintf(os.Stderr, "not logged in to context %s\n", context.Name)
+		return nil
+	}
+
+	context.Token = ""
+
+	if err := config.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully logged out of context %s\n", context.Name)
+	return nil
+}
+
+// selectedContext returns the context picked by the global flag or the
+// current context of the configuration.
+func selectedContext(c *cli.Context, config *Config) *ConfigContext {
+	if name := c.String("context"); name != "" {
+		return config.Get(name)
+	}
+
+	return config.Active()
+}
+
+// readPassword reads the password from stdin, it doesn't echo the input if
+// stdin is attached to a terminal.
+func readPassword(reader *bufio.Reader, stdin bool) (string, error) {
+	if stdin {
+		content, err := ioutil.ReadAll(reader)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to read password")
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("stdin is not a terminal, use --password-stdin")
+	}
+
+	fmt.Fprintf(os.Stderr, "Password: ")
+	content, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintf(os.Stderr, "\n")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read password")
+	}
+
+	return string(content), nil
+}
@@ -40,6 +40,18 @@ func main() {
 				Usage:   "api token",
 				EnvVars: []string{"KLEISTER_TOKEN"},
 			},
+			&cli.StringFlag{
+				Name:    "context",
+				Value:   "",
+				Usage:   "named server context",
+				EnvVars: []string{"KLEISTER_CONTEXT"},
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Value:   "text",
+				Usage:   "output format, can be text, table, json, jsonl, yaml, csv or xml",
+				EnvVars: []string{"KLEISTER_OUTPUT"},
+			},
 		},
 
 		Commands: []*cli.Command{
@@ -54,9 +66,15 @@ func main() {
 			Client(),
 			Profile(),
 			Key(),
+			Migrate(),
+			Context(),
+			Login(),
+			Logout(),
 		},
 	}
 
+	LegacyOutputFlags(app.Commands)
+
 	cli.HelpFlag = &cli.BoolFlag{
 		Name:    "help",
 		Aliases: []string{"h"},
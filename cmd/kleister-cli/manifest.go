@@ -0,0 +1,834 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/guregu/null.v3"
+	"gopkg.in/yaml.v2"
+)
+
+// Manifest represents a declarative definition of a pack.
+type Manifest struct {
+	Slug        string           `json:"slug" yaml:"slug" toml:"slug"`
+	Name        string           `json:"name" yaml:"name" toml:"name"`
+	Website     string           `json:"website,omitempty" yaml:"website,omitempty" toml:"website,omitempty"`
+	Recommended string           `json:"recommended,omitempty" yaml:"recommended,omitempty" toml:"recommended,omitempty"`
+	Latest      string           `json:"latest,omitempty" yaml:"latest,omitempty" toml:"latest,omitempty"`
+	Published   bool             `json:"published" yaml:"published" toml:"published"`
+	Private     bool             `json:"private" yaml:"private" toml:"private"`
+	Icon        string           `json:"icon,omitempty" yaml:"icon,omitempty" toml:"icon,omitempty"`
+	Logo        string           `json:"logo,omitempty" yaml:"logo,omitempty" toml:"logo,omitempty"`
+	Background  string           `json:"background,omitempty" yaml:"background,omitempty" toml:"background,omitempty"`
+	Builds      []*ManifestBuild `json:"builds,omitempty" yaml:"builds,omitempty" toml:"builds,omitempty"`
+
+	// base is the directory of the manifest to resolve asset sidecar files.
+	base string
+}
+
+// ManifestBuild represents a build within a pack manifest.
+type ManifestBuild struct {
+	Slug      string   `json:"slug" yaml:"slug" toml:"slug"`
+	Name      string   `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Minecraft string   `json:"minecraft,omitempty" yaml:"minecraft,omitempty" toml:"minecraft,omitempty"`
+	Forge     string   `json:"forge,omitempty" yaml:"forge,omitempty" toml:"forge,omitempty"`
+	MinJava   string   `json:"min_java,omitempty" yaml:"min_java,omitempty" toml:"min_java,omitempty"`
+	MinMemory string   `json:"min_memory,omitempty" yaml:"min_memory,omitempty" toml:"min_memory,omitempty"`
+	Published bool     `json:"published" yaml:"published" toml:"published"`
+	Private   bool     `json:"private" yaml:"private" toml:"private"`
+	Versions  []string `json:"versions,omitempty" yaml:"versions,omitempty" toml:"versions,omitempty"`
+}
+
+// LoadManifest reads and parses a pack manifest, the format gets detected by
+// the file extension and defaults to YAML.
+func LoadManifest(file string) (*Manifest, error) {
+	content, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest")
+	}
+
+	result := &Manifest{
+		base: filepath.Dir(file),
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".toml":
+		if _, err := toml.Decode(string(content), result); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest. %s", err)
+		}
+	case ".json":
+		if err := json.Unmarshal(content, result); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest. %s", err)
+		}
+	default:
+		if err := yaml.Unmarshal(content, result); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest. %s", err)
+		}
+	}
+
+	if result.Slug == "" {
+		return nil, fmt.Errorf("manifest must define a pack slug")
+	}
+
+	if result.Name == "" {
+		return nil, fmt.Errorf("manifest must define a pack name")
+	}
+
+	for _, build := range result.Builds {
+		if build.Slug == "" {
+			return nil, fmt.Errorf("manifest must define a slug for every build")
+		}
+
+		if build.Name == "" {
+			build.Name = build.Slug
+		}
+
+		for _, pin := range build.Versions {
+			if _, _, err := ParseVersionPin(pin); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ParseVersionPin splits a mod@version pin into the mod and version slug.
+func ParseVersionPin(pin string) (string, string, error) {
+	parts := strings.SplitN(pin, "@", 2)
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid version pin %s, expected mod@version", pin)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// ApplyManifest reconciles the pack defined by the manifest against the server.
+func ApplyManifest(client kleister.ClientAPI, manifest *Manifest) error {
+	pack, err := findPack(client, manifest.Slug)
+
+	if err != nil {
+		return err
+	}
+
+	if pack == nil {
+		record := &kleister.Pack{
+			Slug:      manifest.Slug,
+			Name:      manifest.Name,
+			Website:   manifest.Website,
+			Published: manifest.Published,
+			Private:   manifest.Private,
+		}
+
+		if _, err := manifest.applyAssets(record); err != nil {
+			return err
+		}
+
+		pack, err = client.PackPost(
+			record,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "created pack %s\n", manifest.Slug)
+	} else {
+		changed := false
+
+		if pack.Name != manifest.Name {
+			pack.Name = manifest.Name
+			changed = true
+		}
+
+		if pack.Website != manifest.Website {
+			pack.Website = manifest.Website
+			changed = true
+		}
+
+		if pack.Published != manifest.Published {
+			pack.Published = manifest.Published
+			changed = true
+		}
+
+		if pack.Private != manifest.Private {
+			pack.Private = manifest.Private
+			changed = true
+		}
+
+		assets, err := manifest.applyAssets(pack)
+
+		if err != nil {
+			return err
+		}
+
+		if assets {
+			changed = true
+		}
+
+		if changed {
+			pack, err = client.PackPatch(
+				pack,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "updated pack %s\n", manifest.Slug)
+		}
+	}
+
+	builds := make(map[string]*kleister.Build, len(manifest.Builds))
+
+	for _, definition := range manifest.Builds {
+		build, err := applyManifestBuild(client, pack, definition)
+
+		if err != nil {
+			return fmt.Errorf("build %s: %s", definition.Slug, err)
+		}
+
+		builds[definition.Slug] = build
+	}
+
+	changed := false
+
+	for _, pointer := range []struct {
+		slug   string
+		target *null.Int
+	}{
+		{manifest.Recommended, &pack.RecommendedID},
+		{manifest.Latest, &pack.LatestID},
+	} {
+		if pointer.slug == "" {
+			continue
+		}
+
+		build, ok := builds[pointer.slug]
+
+		if !ok {
+			related, err := findBuild(client, pack.Slug, pointer.slug)
+
+			if err != nil {
+				return err
+			}
+
+			if related == nil {
+				return fmt.Errorf("unknown build %s referenced by pack", pointer.slug)
+			}
+
+			build = related
+		}
+
+		if pointer.target.Int64 != build.ID {
+			*pointer.target = null.NewInt(build.ID, build.ID > 0)
+			changed = true
+		}
+	}
+
+	if changed {
+		_, err := client.PackPatch(
+			pack,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "updated build pointers of pack %s\n", manifest.Slug)
+	}
+
+	return nil
+}
+
+// applyAssets attaches the icon, logo and background of the manifest to the
+// pack if their content differs from the uploaded files.
+func (m *Manifest) applyAssets(pack *kleister.Pack) (bool, error) {
+	changed := false
+
+	for _, asset := range []struct {
+		name   string
+		value  string
+		target **kleister.Attachment
+	}{
+		{"icon", m.Icon, &pack.Icon},
+		{"logo", m.Logo, &pack.Logo},
+		{"background", m.Background, &pack.Background},
+	} {
+		if asset.value == "" {
+			continue
+		}
+
+		content, err := m.loadAsset(asset.value)
+
+		if err != nil {
+			return false, fmt.Errorf("failed to load %s. %s", asset.name, err)
+		}
+
+		if current := *asset.target; current != nil && current.MD5 == fmt.Sprintf("%x", md5.Sum(content)) {
+			continue
+		}
+
+		*asset.target = &kleister.Attachment{
+			Upload: encodeDataURL(content),
+		}
+
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// loadAsset resolves an asset reference which could be an inline data URL, a
+// remote URL or a sidecar file relative to the manifest.
+func (m *Manifest) loadAsset(value string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(value, "data:"):
+		return decodeDataURL(value)
+	case strings.HasPrefix(value, "http://"), strings.HasPrefix(value, "https://"):
+		return downloadAsset(value)
+	default:
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(m.base, value)
+		}
+
+		return ioutil.ReadFile(value)
+	}
+}
+
+// applyManifestBuild reconciles a single build and its version pins.
+func applyManifestBuild(client kleister.ClientAPI, pack *kleister.Pack, definition *ManifestBuild) (*kleister.Build, error) {
+	minecraft := null.Int{}
+
+	if definition.Minecraft != "" {
+		related, err := client.MinecraftGet(
+			definition.Minecraft,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		minecraft = null.NewInt(related.ID, related.ID > 0)
+	}
+
+	forge := null.Int{}
+
+	if definition.Forge != "" {
+		related, err := client.ForgeGet(
+			definition.Forge,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		forge = null.NewInt(related.ID, related.ID > 0)
+	}
+
+	build, err := findBuild(client, pack.Slug, definition.Slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if build == nil {
+		build, err = client.BuildPost(
+			pack.Slug,
+			&kleister.Build{
+				PackID:      pack.ID,
+				Slug:        definition.Slug,
+				Name:        definition.Name,
+				MinecraftID: minecraft,
+				ForgeID:     forge,
+				MinJava:     definition.MinJava,
+				MinMemory:   definition.MinMemory,
+				Published:   definition.Published,
+				Private:     definition.Private,
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(os.Stderr, "created build %s\n", definition.Slug)
+	} else {
+		changed := false
+
+		if build.Name != definition.Name {
+			build.Name = definition.Name
+			changed = true
+		}
+
+		if build.MinecraftID != minecraft {
+			build.MinecraftID = minecraft
+			changed = true
+		}
+
+		if build.ForgeID != forge {
+			build.ForgeID = forge
+			changed = true
+		}
+
+		if build.MinJava != definition.MinJava {
+			build.MinJava = definition.MinJava
+			changed = true
+		}
+
+		if build.MinMemory != definition.MinMemory {
+			build.MinMemory = definition.MinMemory
+			changed = true
+		}
+
+		if build.Published != definition.Published {
+			build.Published = definition.Published
+			changed = true
+		}
+
+		if build.Private != definition.Private {
+			build.Private = definition.Private
+			changed = true
+		}
+
+		if changed {
+			build, err = client.BuildPatch(
+				pack.Slug,
+				build,
+			)
+
+			if err != nil {
+				return nil, err
+			}
+
+			fmt.Fprintf(os.Stderr, "updated build %s\n", definition.Slug)
+		}
+	}
+
+	records, err := client.BuildVersionList(
+		kleister.BuildVersionParams{
+			Pack:  pack.Slug,
+			Build: build.Slug,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool, len(records))
+	resolver := NewModResolver(client)
+
+	for _, record := range records {
+		pin, err := resolver.Pin(record.Version)
+
+		if err != nil {
+			return nil, err
+		}
+
+		current[pin] = true
+	}
+
+	desired := make(map[string]bool, len(definition.Versions))
+
+	for _, pin := range definition.Versions {
+		desired[pin] = true
+
+		if current[pin] {
+			continue
+		}
+
+		mod, version, _ := ParseVersionPin(pin)
+
+		err := client.BuildVersionAppend(
+			kleister.BuildVersionParams{
+				Pack:    pack.Slug,
+				Build:   build.Slug,
+				Mod:     mod,
+				Version: version,
+			},
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to append %s. %s", pin, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "appended %s to build %s\n", pin, build.Slug)
+	}
+
+	for pin := range current {
+		if desired[pin] {
+			continue
+		}
+
+		mod, version, _ := ParseVersionPin(pin)
+
+		err := client.BuildVersionDelete(
+			kleister.BuildVersionParams{
+				Pack:    pack.Slug,
+				Build:   build.Slug,
+				Mod:     mod,
+				Version: version,
+			},
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove %s. %s", pin, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "removed %s from build %s\n", pin, build.Slug)
+	}
+
+	return build, nil
+}
+
+// findPack searches a pack by slug, it returns nil if it doesn't exist.
+func findPack(client kleister.ClientAPI, slug string) (*kleister.Pack, error) {
+	records, err := client.PackList()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.Slug == slug {
+			return record, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findBuild searches a build by slug, it returns nil if it doesn't exist.
+func findBuild(client kleister.ClientAPI, pack, slug string) (*kleister.Build, error) {
+	records, err := client.BuildList(
+		pack,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.Slug == slug {
+			return record, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ModResolver resolves and caches the mods related to versions.
+type ModResolver struct {
+	client kleister.ClientAPI
+	mods   map[int64]*kleister.Mod
+}
+
+// NewModResolver initializes a new mod resolver.
+func NewModResolver(client kleister.ClientAPI) *ModResolver {
+	return &ModResolver{
+		client: client,
+		mods:   make(map[int64]*kleister.Mod),
+	}
+}
+
+// Mod returns the mod related to the version.
+func (r *ModResolver) Mod(version *kleister.Version) (*kleister.Mod, error) {
+	if version.Mod != nil {
+		return version.Mod, nil
+	}
+
+	if mod, ok := r.mods[version.ModID]; ok {
+		return mod, nil
+	}
+
+	mod, err := r.client.ModGet(
+		strconv.FormatInt(version.ModID, 10),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.mods[version.ModID] = mod
+	return mod, nil
+}
+
+// Pin returns the mod@version pin for the version.
+func (r *ModResolver) Pin(version *kleister.Version) (string, error) {
+	mod, err := r.Mod(version)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s@%s", mod.Slug, version.Slug), nil
+}
+
+// WriteManifest writes the manifest to the path, the format gets detected by
+// the file extension and defaults to YAML. An empty path writes to stdout.
+func WriteManifest(file string, manifest *Manifest) error {
+	var (
+		content []byte
+		err     error
+	)
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".toml":
+		buf := new(strings.Builder)
+		err = toml.NewEncoder(buf).Encode(manifest)
+		content = []byte(buf.String())
+	case ".json":
+		content, err = json.MarshalIndent(manifest, "", "  ")
+		content = append(content, '\n')
+	default:
+		content, err = yaml.Marshal(manifest)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest. %s", err)
+	}
+
+	if file == "" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest")
+	}
+
+	return nil
+}
+
+// ExportManifest walks the pack, its builds and their versions to generate a
+// manifest. Assets are inlined as data URLs, or written as sidecar files into
+// the assets directory if it is not empty.
+func ExportManifest(client kleister.ClientAPI, id, assets string) (*Manifest, error) {
+	pack, err := client.PackGet(
+		id,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Manifest{
+		Slug:      pack.Slug,
+		Name:      pack.Name,
+		Website:   pack.Website,
+		Published: pack.Published,
+		Private:   pack.Private,
+		base:      assets,
+	}
+
+	for _, asset := range []struct {
+		name   string
+		source *kleister.Attachment
+		target *string
+	}{
+		{"icon", pack.Icon, &result.Icon},
+		{"logo", pack.Logo, &result.Logo},
+		{"background", pack.Background, &result.Background},
+	} {
+		if asset.source == nil || asset.source.URL == "" {
+			continue
+		}
+
+		value, err := result.storeAsset(asset.name, asset.source.URL)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s. %s", asset.name, err)
+		}
+
+		*asset.target = value
+	}
+
+	builds, err := client.BuildList(
+		pack.Slug,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := NewModResolver(client)
+
+	for _, row := range builds {
+		build, definition, err := ExportBuild(
+			client,
+			resolver,
+			pack.Slug,
+			row.Slug,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if build.ID == pack.RecommendedID.Int64 && pack.RecommendedID.Valid {
+			result.Recommended = build.Slug
+		}
+
+		if build.ID == pack.LatestID.Int64 && pack.LatestID.Valid {
+			result.Latest = build.Slug
+		}
+
+		result.Builds = append(result.Builds, definition)
+	}
+
+	return result, nil
+}
+
+// ExportBuild fetches a build and converts it to a manifest definition.
+func ExportBuild(client kleister.ClientAPI, resolver *ModResolver, pack, id string) (*kleister.Build, *ManifestBuild, error) {
+	build, err := client.BuildGet(
+		pack,
+		id,
+	)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	definition := &ManifestBuild{
+		Slug:      build.Slug,
+		Name:      build.Name,
+		MinJava:   build.MinJava,
+		MinMemory: build.MinMemory,
+		Published: build.Published,
+		Private:   build.Private,
+	}
+
+	minecraft, forge, err := ResolveLoader(
+		client,
+		build,
+	)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if minecraft != nil {
+		definition.Minecraft = minecraft.Slug
+	}
+
+	if forge != nil {
+		definition.Forge = forge.Slug
+	}
+
+	records, err := client.BuildVersionList(
+		kleister.BuildVersionParams{
+			Pack:  pack,
+			Build: build.Slug,
+		},
+	)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, record := range records {
+		pin, err := resolver.Pin(record.Version)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		definition.Versions = append(definition.Versions, pin)
+	}
+
+	sort.Strings(definition.Versions)
+	return build, definition, nil
+}
+
+// storeAsset downloads an asset and returns it as data URL, or writes it as a
+// sidecar file and returns the filename if an assets directory is defined.
+func (m *Manifest) storeAsset(name, rawurl string) (string, error) {
+	content, err := downloadAsset(rawurl)
+
+	if err != nil {
+		return "", err
+	}
+
+	if m.base == "" {
+		return encodeDataURL(content), nil
+	}
+
+	ext := ""
+
+	if uri, err := url.Parse(rawurl); err == nil {
+		ext = path.Ext(uri.Path)
+	}
+
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(http.DetectContentType(content)); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+
+	filename := fmt.Sprintf("%s-%s%s", m.Slug, name, ext)
+
+	if err := os.MkdirAll(m.base, 0755); err != nil {
+		return "", err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(m.base, filename), content, 0644); err != nil {
+		return "", err
+	}
+
+	return filename, nil
+}
+
+// downloadAsset fetches the content of a remote file.
+func downloadAsset(rawurl string) ([]byte, error) {
+	resp, err := http.Get(rawurl)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s", rawurl)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s, status %d", rawurl, resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// decodeDataURL decodes the content of a base64 encoded data URL.
+func decodeDataURL(value string) ([]byte, error) {
+	idx := strings.Index(value, ";base64,")
+
+	if idx < 0 {
+		return nil, fmt.Errorf("only base64 data URLs are supported")
+	}
+
+	return base64.StdEncoding.DecodeString(value[idx+len(";base64,"):])
+}
+
+// encodeDataURL encodes the content as base64 data URL.
+func encodeDataURL(content []byte) string {
+	return fmt.Sprintf(
+		"data:%s;base64,%s",
+		http.DetectContentType(content),
+		base64.StdEncoding.EncodeToString(content),
+	)
+}
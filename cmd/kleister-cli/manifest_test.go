@@ -0,0 +1,249 @@
+package main
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestApplyManifest(t *testing.T) {
+	dir := t.TempDir()
+	icon := []byte("\x89PNG\r\n\x1a\nicon")
+	logo := []byte("\x89PNG\r\n\x1a\nlogo")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "icon.png"), icon, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient()
+	client.minecraft = []*kleister.Minecraft{{ID: 1, Slug: "1.12.2", Version: "1.12.2"}}
+	client.forge = []*kleister.Forge{{ID: 2, Slug: "14.23.5.2847", Version: "14.23.5.2847"}}
+	client.mods = []*kleister.Mod{{ID: 3, Slug: "jei"}, {ID: 4, Slug: "baubles"}}
+	client.versions = []*kleister.Version{
+		{ID: 10, ModID: 3, Slug: "4.15.0"},
+		{ID: 11, ModID: 3, Slug: "4.16.0"},
+		{ID: 12, ModID: 4, Slug: "1.5.2"},
+	}
+
+	steps := []struct {
+		name     string
+		manifest string
+		calls    []string
+	}{
+		{
+			name: "create",
+			manifest: `slug: demo
+name: Demo
+icon: icon.png
+logo: ` + encodeDataURL(logo) + `
+recommended: 1.0.0
+latest: 1.0.0
+builds:
+- slug: 1.0.0
+  minecraft: 1.12.2
+  forge: 14.23.5.2847
+  versions:
+  - jei@4.15.0
+  - baubles@1.5.2
+`,
+			calls: []string{"BuildPost", "BuildVersionAppend", "BuildVersionAppend", "PackPatch", "PackPost"},
+		},
+		{
+			name: "unchanged",
+			manifest: `slug: demo
+name: Demo
+icon: icon.png
+logo: ` + encodeDataURL(logo) + `
+recommended: 1.0.0
+latest: 1.0.0
+builds:
+- slug: 1.0.0
+  minecraft: 1.12.2
+  forge: 14.23.5.2847
+  versions:
+  - jei@4.15.0
+  - baubles@1.5.2
+`,
+			calls: []string{},
+		},
+		{
+			name: "update",
+			manifest: `slug: demo
+name: Demo Pack
+icon: icon.png
+logo: ` + encodeDataURL(logo) + `
+recommended: 1.0.0
+latest: 1.1.0
+builds:
+- slug: 1.0.0
+  minecraft: 1.12.2
+  forge: 14.23.5.2847
+  min_memory: "4096"
+  versions:
+  - jei@4.16.0
+- slug: 1.1.0
+  minecraft: 1.12.2
+  versions:
+  - jei@4.16.0
+`,
+			calls: []string{
+				"BuildPatch",
+				"BuildPost",
+				"BuildVersionAppend",
+				"BuildVersionAppend",
+				"BuildVersionDelete",
+				"BuildVersionDelete",
+				"PackPatch",
+				"PackPatch",
+			},
+		},
+	}
+
+	for _, step := range steps {
+		file := filepath.Join(dir, "manifest.yml")
+
+		if err := ioutil.WriteFile(file, []byte(step.manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		manifest, err := LoadManifest(file)
+
+		if err != nil {
+			t.Fatalf("%s: %s", step.name, err)
+		}
+
+		client.calls = []string{}
+
+		if err := ApplyManifest(client, manifest); err != nil {
+			t.Fatalf("%s: %s", step.name, err)
+		}
+
+		sort.Strings(client.calls)
+
+		if !reflect.DeepEqual(client.calls, step.calls) {
+			t.Errorf("%s: expected calls %v, got %v", step.name, step.calls, client.calls)
+		}
+	}
+
+	pack := client.pack("demo")
+
+	if pack == nil || pack.Name != "Demo Pack" {
+		t.Fatalf("expected pack to be renamed, got %+v", pack)
+	}
+
+	for _, asset := range []struct {
+		name   string
+		value  *kleister.Attachment
+		source []byte
+	}{
+		{"icon", pack.Icon, icon},
+		{"logo", pack.Logo, logo},
+	} {
+		if want := fmt.Sprintf("%x", md5.Sum(asset.source)); asset.value == nil || asset.value.MD5 != want {
+			t.Errorf("expected %s with checksum %s, got %+v", asset.name, want, asset.value)
+		}
+	}
+
+	if pack.Background != nil {
+		t.Errorf("expected no background, got %+v", pack.Background)
+	}
+
+	stable := client.build("demo", "1.0.0")
+	latest := client.build("demo", "1.1.0")
+
+	if stable == nil || latest == nil {
+		t.Fatalf("expected builds 1.0.0 and 1.1.0")
+	}
+
+	if stable.MinMemory != "4096" || stable.MinecraftID.Int64 != 1 || stable.ForgeID.Int64 != 2 {
+		t.Errorf("expected build 1.0.0 to be updated, got %+v", stable)
+	}
+
+	if latest.ForgeID.Valid {
+		t.Errorf("expected build 1.1.0 without forge, got %+v", latest.ForgeID)
+	}
+
+	if pack.RecommendedID.Int64 != stable.ID || pack.LatestID.Int64 != latest.ID {
+		t.Errorf("expected pointers %d and %d, got %d and %d", stable.ID, latest.ID, pack.RecommendedID.Int64, pack.LatestID.Int64)
+	}
+
+	for _, build := range []*kleister.Build{stable, latest} {
+		if want := []int64{11}; !reflect.DeepEqual(client.pins[build.ID], want) {
+			t.Errorf("build %s: expected pins %v, got %v", build.Slug, want, client.pins[build.ID])
+		}
+	}
+}
+
+func TestApplyManifestAssetChanged(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "icon.png")
+
+	client := newTestClient()
+	client.packs = []*kleister.Pack{{ID: 1, Slug: "demo", Name: "Demo"}}
+
+	for _, content := range []string{"first", "second", "second"} {
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		client.calls = []string{}
+		manifest := &Manifest{Slug: "demo", Name: "Demo", Icon: "icon.png", base: dir}
+
+		if err := ApplyManifest(client, manifest); err != nil {
+			t.Fatal(err)
+		}
+
+		if want := fmt.Sprintf("%x", md5.Sum([]byte(content))); client.packs[0].Icon == nil || client.packs[0].Icon.MD5 != want {
+			t.Errorf("%s: expected icon checksum %s, got %+v", content, want, client.packs[0].Icon)
+		}
+
+		if len(client.calls) > 1 {
+			t.Errorf("%s: expected at most one update, got %v", content, client.calls)
+		}
+	}
+
+	if len(client.calls) != 0 {
+		t.Errorf("expected no update for an unchanged icon, got %v", client.calls)
+	}
+}
+
+func TestManifestLoadAsset(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "logo.png"), []byte("sidecar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &Manifest{base: dir}
+
+	tests := []struct {
+		value string
+		want  string
+		err   bool
+	}{
+		{"logo.png", "sidecar", false},
+		{filepath.Join(dir, "logo.png"), "sidecar", false},
+		{encodeDataURL([]byte("inline")), "inline", false},
+		{"data:image/png,inline", "", true},
+		{"missing.png", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := manifest.loadAsset(tt.value)
+
+		if tt.err != (err != nil) {
+			t.Errorf("%s: expected failure %v, got %v", tt.value, tt.err, err)
+			continue
+		}
+
+		if string(got) != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
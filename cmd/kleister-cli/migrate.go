@@ -0,0 +1,456 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/urfave/cli.v2"
+)
+
+// Migrate provides the sub-command to migrate records between servers.
+func Migrate() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "migrate records between servers",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "pack",
+				Usage:     "copy a pack with builds, mods and versions",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "pack id or slug to migrate",
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Value: "",
+						Usage: "source api server or context, defaults to the global server",
+					},
+					&cli.StringFlag{
+						Name:    "from-token",
+						Value:   "",
+						Usage:   "api token for the source server",
+						EnvVars: []string{"KLEISTER_FROM_TOKEN"},
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Value: "",
+						Usage: "target api server or context",
+					},
+					&cli.StringFlag{
+						Name:    "to-token",
+						Value:   "",
+						Usage:   "api token for the target server",
+						EnvVars: []string{"KLEISTER_TO_TOKEN"},
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, MigratePack)
+				},
+			},
+		},
+	}
+}
+
+// MigratePack provides the sub-command to copy a pack to another server.
+func MigratePack(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("to") == "" {
+		return fmt.Errorf("you must provide a target server")
+	}
+
+	source := client
+
+	if c.String("from") != "" {
+		source = NewClient(
+			ResolveContext(
+				c.String("from"),
+				c.String("from-token"),
+			),
+		)
+	}
+
+	target := NewClient(
+		ResolveContext(
+			c.String("to"),
+			c.String("to-token"),
+		),
+	)
+
+	manifest, err := ExportManifest(
+		source,
+		GetIdentifierParam(c),
+		"",
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := migrateVersions(source, target, manifest); err != nil {
+		return err
+	}
+
+	if err := ApplyManifest(target, manifest); err != nil {
+		return err
+	}
+
+	if err := migrateAssignments(source, target, manifest.Slug); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "successfully migrated\n")
+	return nil
+}
+
+// migrateVersions creates all mods and versions pinned by the manifest which
+// are missing on the target, including their files.
+func migrateVersions(source, target kleister.ClientAPI, manifest *Manifest) error {
+	records, err := target.ModList()
+
+	if err != nil {
+		return err
+	}
+
+	mods := make(map[string]*kleister.Mod, len(records))
+	versions := make(map[string]map[string]bool)
+
+	for _, record := range records {
+		mods[record.Slug] = record
+	}
+
+	for _, build := range manifest.Builds {
+		for _, pin := range build.Versions {
+			modSlug, versionSlug, _ := ParseVersionPin(pin)
+
+			mod, ok := mods[modSlug]
+
+			if !ok {
+				related, err := source.ModGet(
+					modSlug,
+				)
+
+				if err != nil {
+					return err
+				}
+
+				mod, err = target.ModPost(
+					&kleister.Mod{
+						Slug:        related.Slug,
+						Name:        related.Name,
+						Side:        related.Side,
+						Description: related.Description,
+						Author:      related.Author,
+						Website:     related.Website,
+						Donate:      related.Donate,
+					},
+				)
+
+				if err != nil {
+					return fmt.Errorf("failed to create mod %s. %s", modSlug, err)
+				}
+
+				mods[modSlug] = mod
+				fmt.Fprintf(os.Stderr, "created mod %s\n", modSlug)
+			}
+
+			if _, ok := versions[modSlug]; !ok {
+				existing, err := target.VersionList(
+					mod.Slug,
+				)
+
+				if err != nil {
+					return err
+				}
+
+				versions[modSlug] = make(map[string]bool, len(existing))
+
+				for _, version := range existing {
+					versions[modSlug][version.Slug] = true
+				}
+			}
+
+			if versions[modSlug][versionSlug] {
+				continue
+			}
+
+			related, err := source.VersionGet(
+				modSlug,
+				versionSlug,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			record := &kleister.Version{
+				ModID: mod.ID,
+				Slug:  related.Slug,
+				Name:  related.Name,
+			}
+
+			if related.File != nil && related.File.URL != "" {
+				if err := record.DownloadFile(related.File.URL); err != nil {
+					return fmt.Errorf("failed to download file of %s", pin)
+				}
+			}
+
+			if _, err := target.VersionPost(mod.Slug, record); err != nil {
+				return fmt.Errorf("failed to create version %s. %s", pin, err)
+			}
+
+			versions[modSlug][versionSlug] = true
+			fmt.Fprintf(os.Stderr, "created version %s\n", pin)
+		}
+	}
+
+	return nil
+}
+
+// migrateAssignments copies the client, user and team assignments of the pack,
+// records which don't exist on the target get skipped with a warning.
+func migrateAssignments(source, target kleister.ClientAPI, pack string) error {
+	sourceClients, err := source.PackClientList(
+		kleister.PackClientParams{
+			Pack: pack,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	targetClients, err := target.PackClientList(
+		kleister.PackClientParams{
+			Pack: pack,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	clients, err := target.ClientList()
+
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range sourceClients {
+		if assignment.Client == nil {
+			continue
+		}
+
+		slug := assignment.Client.Slug
+
+		if !hasClient(clients, slug) {
+			fmt.Fprintf(os.Stderr, "warning: client %s doesn't exist on target, skipping\n", slug)
+			continue
+		}
+
+		if hasClientAssignment(targetClients, slug) {
+			continue
+		}
+
+		err := target.PackClientAppend(
+			kleister.PackClientParams{
+				Pack:   pack,
+				Client: slug,
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "appended client %s\n", slug)
+	}
+
+	sourceUsers, err := source.PackUserList(
+		kleister.PackUserParams{
+			Pack: pack,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	targetUsers, err := target.PackUserList(
+		kleister.PackUserParams{
+			Pack: pack,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	users, err := target.UserList()
+
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range sourceUsers {
+		if assignment.User == nil {
+			continue
+		}
+
+		slug := assignment.User.Slug
+
+		if !hasUser(users, slug) {
+			fmt.Fprintf(os.Stderr, "warning: user %s doesn't exist on target, skipping\n", slug)
+			continue
+		}
+
+		params := kleister.PackUserParams{
+			Pack: pack,
+			User: slug,
+			Perm: assignment.Perm,
+		}
+
+		if current, ok := findUserAssignment(targetUsers, slug); !ok {
+			if err := target.PackUserAppend(params); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "appended user %s\n", slug)
+		} else if current.Perm != assignment.Perm {
+			if err := target.PackUserPerm(params); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "updated permissions of user %s\n", slug)
+		}
+	}
+
+	sourceTeams, err := source.PackTeamList(
+		kleister.PackTeamParams{
+			Pack: pack,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	targetTeams, err := target.PackTeamList(
+		kleister.PackTeamParams{
+			Pack: pack,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	teams, err := target.TeamList()
+
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range sourceTeams {
+		if assignment.Team == nil {
+			continue
+		}
+
+		slug := assignment.Team.Slug
+
+		if !hasTeam(teams, slug) {
+			fmt.Fprintf(os.Stderr, "warning: team %s doesn't exist on target, skipping\n", slug)
+			continue
+		}
+
+		params := kleister.PackTeamParams{
+			Pack: pack,
+			Team: slug,
+			Perm: assignment.Perm,
+		}
+
+		if current, ok := findTeamAssignment(targetTeams, slug); !ok {
+			if err := target.PackTeamAppend(params); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "appended team %s\n", slug)
+		} else if current.Perm != assignment.Perm {
+			if err := target.PackTeamPerm(params); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "updated permissions of team %s\n", slug)
+		}
+	}
+
+	return nil
+}
+
+// hasClient checks if a client with the slug is part of the list.
+func hasClient(records []*kleister.Client, slug string) bool {
+	for _, record := range records {
+		if record.Slug == slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasClientAssignment checks if a client with the slug is assigned.
+func hasClientAssignment(records []*kleister.ClientPack, slug string) bool {
+	for _, record := range records {
+		if record.Client != nil && record.Client.Slug == slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasUser checks if a user with the slug is part of the list.
+func hasUser(records []*kleister.User, slug string) bool {
+	for _, record := range records {
+		if record.Slug == slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findUserAssignment searches the assignment of the user with the slug.
+func findUserAssignment(records []*kleister.UserPack, slug string) (*kleister.UserPack, bool) {
+	for _, record := range records {
+		if record.User != nil && record.User.Slug == slug {
+			return record, true
+		}
+	}
+
+	return nil, false
+}
+
+// hasTeam checks if a team with the slug is part of the list.
+func hasTeam(records []*kleister.Team, slug string) bool {
+	for _, record := range records {
+		if record.Slug == slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findTeamAssignment searches the assignment of the team with the slug.
+func findTeamAssignment(records []*kleister.TeamPack, slug string) (*kleister.TeamPack, bool) {
+	for _, record := range records {
+		if record.Team != nil && record.Team.Slug == slug {
+			return record, true
+		}
+	}
+
+	return nil, false
+}
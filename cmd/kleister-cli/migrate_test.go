@@ -0,0 +1,168 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestMigrateVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("jar content"))
+	}))
+
+	defer server.Close()
+
+	source := newTestClient()
+	source.mods = []*kleister.Mod{
+		{ID: 1, Slug: "jei", Name: "Just Enough Items"},
+		{ID: 2, Slug: "baubles", Name: "Baubles", Side: "both", Author: "Azanor", Website: "https://example.com"},
+	}
+	source.versions = []*kleister.Version{
+		{ID: 10, ModID: 1, Slug: "4.15.0", Name: "4.15.0"},
+		{ID: 11, ModID: 1, Slug: "4.16.0", Name: "4.16.0", File: &kleister.Attachment{URL: server.URL + "/jei.jar"}},
+		{ID: 12, ModID: 2, Slug: "1.5.2", Name: "1.5.2 final"},
+	}
+
+	target := newTestClient()
+	target.mods = []*kleister.Mod{{ID: 3, Slug: "jei", Name: "JEI"}}
+	target.versions = []*kleister.Version{{ID: 20, ModID: 3, Slug: "4.15.0", Name: "4.15.0"}}
+
+	manifest := &Manifest{
+		Slug: "demo",
+		Builds: []*ManifestBuild{
+			{Slug: "1.0.0", Versions: []string{"jei@4.15.0", "baubles@1.5.2"}},
+			{Slug: "1.1.0", Versions: []string{"jei@4.16.0", "baubles@1.5.2"}},
+		},
+	}
+
+	if err := migrateVersions(source, target, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(target.calls)
+
+	if want := []string{"ModPost", "VersionPost", "VersionPost"}; !reflect.DeepEqual(target.calls, want) {
+		t.Errorf("expected calls %v, got %v", want, target.calls)
+	}
+
+	if mod := target.mod("jei"); mod.Name != "JEI" {
+		t.Errorf("expected existing mod to stay untouched, got %+v", mod)
+	}
+
+	mod := target.mod("baubles")
+
+	if mod == nil {
+		t.Fatalf("expected mod baubles to be created")
+	}
+
+	if mod.ID == 2 || mod.Name != "Baubles" || mod.Side != "both" || mod.Author != "Azanor" || mod.Website != "https://example.com" {
+		t.Errorf("expected mod baubles to be copied, got %+v", mod)
+	}
+
+	if version := target.version("baubles", "1.5.2"); version == nil || version.Name != "1.5.2 final" || version.File != nil {
+		t.Errorf("expected version baubles@1.5.2 without file, got %+v", version)
+	}
+
+	version := target.version("jei", "4.16.0")
+
+	if version == nil || version.ModID != 3 {
+		t.Fatalf("expected version jei@4.16.0 for the existing mod, got %+v", version)
+	}
+
+	if version.File == nil {
+		t.Fatalf("expected file of jei@4.16.0 to be uploaded")
+	}
+
+	if content, err := decodeDataURL(version.File.Upload); err != nil || string(content) != "jar content" {
+		t.Errorf("expected uploaded file content, got %q %v", content, err)
+	}
+
+	target.calls = []string{}
+
+	if err := migrateVersions(source, target, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(target.calls) != 0 {
+		t.Errorf("expected existing versions to be skipped, got %v", target.calls)
+	}
+}
+
+func TestMigrateAssignments(t *testing.T) {
+	pack := &kleister.Pack{Slug: "demo"}
+
+	source := newTestClient()
+	source.packClients = []*kleister.ClientPack{
+		{Client: &kleister.Client{Slug: "launcher"}, Pack: pack},
+		{Client: &kleister.Client{Slug: "website"}, Pack: pack},
+		{Client: &kleister.Client{Slug: "missing"}, Pack: pack},
+	}
+	source.packUsers = []*kleister.UserPack{
+		{User: &kleister.User{Slug: "admin"}, Pack: pack, Perm: "owner"},
+		{User: &kleister.User{Slug: "guest"}, Pack: pack, Perm: "user"},
+		{User: &kleister.User{Slug: "missing"}, Pack: pack, Perm: "user"},
+	}
+	source.packTeams = []*kleister.TeamPack{
+		{Team: &kleister.Team{Slug: "devs"}, Pack: pack, Perm: "admin"},
+		{Team: &kleister.Team{Slug: "testers"}, Pack: pack, Perm: "user"},
+	}
+
+	target := newTestClient()
+	target.clients = []*kleister.Client{{Slug: "launcher"}, {Slug: "website"}}
+	target.users = []*kleister.User{{Slug: "admin"}, {Slug: "guest"}}
+	target.teams = []*kleister.Team{{Slug: "devs"}, {Slug: "testers"}}
+	target.packClients = []*kleister.ClientPack{
+		{Client: &kleister.Client{Slug: "website"}, Pack: pack},
+	}
+	target.packUsers = []*kleister.UserPack{
+		{User: &kleister.User{Slug: "admin"}, Pack: pack, Perm: "user"},
+	}
+	target.packTeams = []*kleister.TeamPack{
+		{Team: &kleister.Team{Slug: "devs"}, Pack: pack, Perm: "admin"},
+	}
+
+	if err := migrateAssignments(source, target, "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(target.calls)
+
+	if want := []string{"PackClientAppend", "PackTeamAppend", "PackUserAppend", "PackUserPerm"}; !reflect.DeepEqual(target.calls, want) {
+		t.Errorf("expected calls %v, got %v", want, target.calls)
+	}
+
+	users := make(map[string]string)
+
+	for _, record := range target.packUsers {
+		users[record.User.Slug] = record.Perm
+	}
+
+	if want := map[string]string{"admin": "owner", "guest": "user"}; !reflect.DeepEqual(users, want) {
+		t.Errorf("expected users %v, got %v", want, users)
+	}
+
+	teams := make(map[string]string)
+
+	for _, record := range target.packTeams {
+		teams[record.Team.Slug] = record.Perm
+	}
+
+	if want := map[string]string{"devs": "admin", "testers": "user"}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("expected teams %v, got %v", want, teams)
+	}
+
+	target.calls = []string{}
+
+	if err := migrateAssignments(source, target, "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(target.calls) != 0 {
+		t.Errorf("expected existing assignments to be skipped, got %v", target.calls)
+	}
+}
@@ -1,15 +1,11 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
-	"text/template"
 
-	"github.com/Knetic/govaluate"
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
 )
@@ -45,21 +41,16 @@ func Minecraft() *cli.Command {
 						Value: "slug",
 						Usage: "Sort by this field",
 					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "Sort by a field, prefix with a dash for descending order",
+					},
 					&cli.StringFlag{
 						Name:  "format",
 						Value: tmplMinecraftList,
 						Usage: "Custom output format",
 					},
-					&cli.BoolFlag{
-						Name:  "json",
-						Value: false,
-						Usage: "Print in JSON format",
-					},
-					&cli.BoolFlag{
-						Name:  "xml",
-						Value: false,
-						Usage: "Print in XML format",
-					},
 					&cli.StringFlag{
 						Name:  "filter",
 						Value: "",
@@ -75,6 +66,11 @@ func Minecraft() *cli.Command {
 						Value: false,
 						Usage: "Return only last record",
 					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "Comma separated list of columns for table and csv output",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, MinecraftList)
@@ -109,15 +105,30 @@ func Minecraft() *cli.Command {
 								Value: tmplMinecraftBuildList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -183,140 +194,36 @@ func Minecraft() *cli.Command {
 
 // MinecraftList provides the sub-command to list all Minecraft versions.
 func MinecraftList(c *cli.Context, client kleister.ClientAPI) error {
-	var (
-		result []*kleister.Minecraft
-	)
-
 	records, err := client.MinecraftList()
 
 	if err != nil {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.IsSet("first") && c.IsSet("last") {
-		return fmt.Errorf("conflict, you can only use first or last at once")
-	}
-
-	if c.IsSet("filter") {
-		expression, err := govaluate.NewEvaluableExpression(
-			c.String("filter"),
-		)
-
-		if err != nil {
-			return fmt.Errorf("failed to parse filter. %s", err)
-		}
-
-		for _, record := range records {
-			params := make(map[string]interface{}, 3)
-			params["Slug"] = record.Slug
-			params["Version"] = record.Version
-			params["Type"] = record.Type
-
-			match, err := expression.Evaluate(
-				params,
-			)
-
-			if err != nil {
-				return fmt.Errorf("failed to evaluate filter. %s", err)
-			}
-
-			if match.(bool) {
-				result = append(result, record)
-			}
-		}
-	} else {
-		result = records
-	}
-
 	switch strings.ToLower(c.String("sort")) {
 	case "slug":
 		sort.Sort(
 			kleister.MinecraftBySlug(
-				result,
+				records,
 			),
 		)
 	case "version":
 		sort.Sort(
 			kleister.MinecraftByVersion(
-				result,
+				records,
 			),
 		)
 	case "type":
 		sort.Sort(
 			kleister.MinecraftByType(
-				result,
+				records,
 			),
 		)
 	default:
 		return fmt.Errorf("the sort value %s is invalid, can be slug, version or type", c.String("sort"))
 	}
 
-	if c.Bool("first") {
-		result = []*kleister.Minecraft{
-			result[0],
-		}
-	}
-
-	if c.Bool("last") {
-		result = []*kleister.Minecraft{
-			result[len(result)-1],
-		}
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(result, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(result, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(result) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range result {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // MinecraftRefresh provides the sub-command to refresh the Minecraft versions.
@@ -343,60 +250,7 @@ func MinecraftBuildList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // MinecraftBuildAppend provides the sub-command to append a build to the Minecraft.
@@ -1,11 +1,9 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
-	"text/template"
+	"path/filepath"
 
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
@@ -63,15 +61,30 @@ func Mod() *cli.Command {
 						Value: tmplModList,
 						Usage: "Custom output format",
 					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "Comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "Sort by a field, prefix with a dash for descending order",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Value: "",
+						Usage: "Filter by expression on the record fields",
+					},
 					&cli.BoolFlag{
-						Name:  "json",
+						Name:  "first",
 						Value: false,
-						Usage: "Print in JSON format",
+						Usage: "Return only first record",
 					},
 					&cli.BoolFlag{
-						Name:  "xml",
+						Name:  "last",
 						Value: false,
-						Usage: "Print in XML format",
+						Usage: "Return only last record",
 					},
 				},
 				Action: func(c *cli.Context) error {
@@ -93,16 +106,6 @@ func Mod() *cli.Command {
 						Value: tmplModShow,
 						Usage: "Custom output format",
 					},
-					&cli.BoolFlag{
-						Name:  "json",
-						Value: false,
-						Usage: "Print in JSON format",
-					},
-					&cli.BoolFlag{
-						Name:  "xml",
-						Value: false,
-						Usage: "Print in XML format",
-					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, ModShow)
@@ -219,6 +222,36 @@ func Mod() *cli.Command {
 					return Handle(c, ModCreate)
 				},
 			},
+			{
+				Name:      "import",
+				Usage:     "import mods and versions from a directory of jars",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Value: "",
+						Usage: "directory containing the mod jars",
+					},
+					&cli.StringFlag{
+						Name:  "pack",
+						Value: "",
+						Usage: "id or slug of the pack for the build",
+					},
+					&cli.StringFlag{
+						Name:  "build",
+						Value: "",
+						Usage: "id or slug of a build to append the versions",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Value: false,
+						Usage: "upload files even if the mod already got them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, ModImport)
+				},
+			},
 			{
 				Name:  "user",
 				Usage: "User assignments",
@@ -239,15 +272,30 @@ func Mod() *cli.Command {
 								Value: tmplModUserList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -347,15 +395,30 @@ func Mod() *cli.Command {
 								Value: tmplModTeamList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -447,60 +510,7 @@ func ModList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // ModShow provides the sub-command to show mod details.
@@ -513,47 +523,7 @@ func ModShow(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	return tmpl.Execute(os.Stdout, record)
+	return OutputRecord(c, record)
 }
 
 // ModDelete provides the sub-command to delete a mod.
@@ -680,74 +650,258 @@ func ModCreate(c *cli.Context, client kleister.ClientAPI) error {
 	return nil
 }
 
-// ModUserList provides the sub-command to list users of the mod.
-func ModUserList(c *cli.Context, client kleister.ClientAPI) error {
-	records, err := client.ModUserList(
-		kleister.ModUserParams{
-			Mod: GetIdentifierParam(c),
-		},
+// ModImport provides the sub-command to import a directory of mod jars.
+func ModImport(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("dir") == "" {
+		return fmt.Errorf("you must provide a directory")
+	}
+
+	if c.String("build") != "" && c.String("pack") == "" {
+		return fmt.Errorf("you must provide a pack for the build")
+	}
+
+	files, err := filepath.Glob(
+		filepath.Join(c.String("dir"), "*.jar"),
 	)
 
+	if err != nil || len(files) == 0 {
+		return fmt.Errorf("failed to find jars within %s", c.String("dir"))
+	}
+
+	records, err := client.ModList()
+
 	if err != nil {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
+	mods := make(map[string]*kleister.Mod, len(records))
+
+	for _, record := range records {
+		mods[record.Slug] = record
 	}
 
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
+	pinned := make(map[string]string)
+
+	if c.String("build") != "" {
+		rows, err := client.BuildVersionList(
+			kleister.BuildVersionParams{
+				Pack:  c.String("pack"),
+				Build: c.String("build"),
+			},
+		)
 
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
+		resolver := NewModResolver(client)
+
+		for _, row := range rows {
+			mod, err := resolver.Mod(row.Version)
+
+			if err != nil {
+				return err
+			}
+
+			pinned[mod.Slug] = row.Version.Slug
+		}
 	}
 
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
+	failed := 0
+
+	for _, file := range files {
+		pin, err := importJar(client, mods, file, c.Bool("force"))
 
 		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+			failed++
+
+			continue
+		}
+
+		if c.String("build") == "" {
+			continue
+		}
+
+		if err := pinImportedVersion(client, c.String("pack"), c.String("build"), pinned, pin); err != nil {
 			return err
 		}
+	}
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d of %d jars", failed, len(files))
 	}
 
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
+	fmt.Fprintf(os.Stderr, "successfully imported %d jars\n", len(files))
+	return nil
+}
+
+// pinImportedVersion replaces the pinned version of the mod within the build,
+// the previous version gets only removed after the new one has been appended.
+func pinImportedVersion(client kleister.ClientAPI, pack, build string, pinned map[string]string, pin string) error {
+	mod, version, _ := ParseVersionPin(pin)
+
+	if pinned[mod] == version {
 		return nil
 	}
 
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
+	err := client.BuildVersionAppend(
+		kleister.BuildVersionParams{
+			Pack:    pack,
+			Build:   build,
+			Mod:     mod,
+			Version: version,
+		},
 	)
 
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to append %s. %s", pin, err)
 	}
 
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
+	fmt.Fprintf(os.Stderr, "appended %s to build\n", pin)
+
+	if previous, ok := pinned[mod]; ok {
+		err := client.BuildVersionDelete(
+			kleister.BuildVersionParams{
+				Pack:    pack,
+				Build:   build,
+				Mod:     mod,
+				Version: previous,
+			},
+		)
 
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to remove %s@%s, the build contains both versions now. %s", mod, previous, err)
 		}
+
+		fmt.Fprintf(os.Stderr, "removed %s@%s from build\n", mod, previous)
 	}
 
+	pinned[mod] = version
 	return nil
 }
 
+// importJar creates the mod and version defined by a jar if they don't exist
+// yet, it returns the pin of the version. Files already uploaded for the mod
+// get skipped unless forced.
+func importJar(client kleister.ClientAPI, mods map[string]*kleister.Mod, file string, force bool) (string, error) {
+	metadata, err := ReadJarMetadata(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	if metadata.ID == "" {
+		return "", fmt.Errorf("missing mod id within %s", file)
+	}
+
+	if metadata.Version == "" {
+		return "", fmt.Errorf("failed to detect the version of %s, create it with version create --name instead", file)
+	}
+
+	mod, ok := mods[metadata.Slug()]
+
+	if !ok {
+		record := &kleister.Mod{
+			Slug:        metadata.Slug(),
+			Name:        metadata.Name,
+			Side:        metadata.Side,
+			Description: metadata.Description,
+			Author:      metadata.Author,
+			Website:     metadata.Website,
+		}
+
+		if record.Name == "" {
+			record.Name = metadata.ID
+		}
+
+		mod, err = client.ModPost(
+			record,
+		)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to create mod %s. %s", metadata.Slug(), err)
+		}
+
+		mods[mod.Slug] = mod
+		fmt.Fprintf(os.Stderr, "created mod %s\n", mod.Slug)
+	}
+
+	slug := Slugify(metadata.Version)
+	pin := fmt.Sprintf("%s@%s", mod.Slug, slug)
+
+	versions, err := client.VersionList(
+		mod.Slug,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, version := range versions {
+		if version.Slug == slug {
+			fmt.Fprintf(os.Stderr, "version %s already exists\n", pin)
+			return pin, nil
+		}
+	}
+
+	record := &kleister.Version{
+		ModID: mod.ID,
+		Slug:  slug,
+		Name:  metadata.Version,
+	}
+
+	if err := record.EncodeFile(file); err != nil {
+		return "", fmt.Errorf("failed to encode %s. %s", file, err)
+	}
+
+	if !force {
+		checksum, err := UploadChecksum(record.File)
+
+		if err != nil {
+			return "", err
+		}
+
+		duplicate, err := FindDuplicate(
+			client,
+			mod.Slug,
+			checksum,
+			0,
+		)
+
+		if err != nil {
+			return "", err
+		}
+
+		if duplicate != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s, file already uploaded as %s@%s\n", file, mod.Slug, duplicate.Slug)
+			return fmt.Sprintf("%s@%s", mod.Slug, duplicate.Slug), nil
+		}
+	}
+
+	if _, err := client.VersionPost(mod.Slug, record); err != nil {
+		return "", fmt.Errorf("failed to create version %s. %s", pin, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "created version %s\n", pin)
+	return pin, nil
+}
+
+// ModUserList provides the sub-command to list users of the mod.
+func ModUserList(c *cli.Context, client kleister.ClientAPI) error {
+	records, err := client.ModUserList(
+		kleister.ModUserParams{
+			Mod: GetIdentifierParam(c),
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return OutputList(c, records)
+}
+
 // ModUserAppend provides the sub-command to append a user to the mod.
 func ModUserAppend(c *cli.Context, client kleister.ClientAPI) error {
 	err := client.ModUserAppend(
@@ -813,60 +967,7 @@ func ModTeamList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // ModTeamAppend provides the sub-command to append a team to the mod.
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestPinImportedVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		fail string
+		pins []int64
+		err  bool
+	}{
+		{"replaced", "", []int64{11}, false},
+		{"append failed", "BuildVersionAppend", []int64{10}, true},
+		{"delete failed", "BuildVersionDelete", []int64{10, 11}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+			client.packs = []*kleister.Pack{{ID: 1, Slug: "demo"}}
+			client.builds = []*kleister.Build{{ID: 2, PackID: 1, Slug: "1.0.0"}}
+			client.mods = []*kleister.Mod{{ID: 3, Slug: "jei"}}
+			client.versions = []*kleister.Version{
+				{ID: 10, ModID: 3, Slug: "4.15.0"},
+				{ID: 11, ModID: 3, Slug: "4.16.0"},
+			}
+			client.pins[2] = []int64{10}
+
+			if tt.fail != "" {
+				client.fail[tt.fail] = errors.New("server error")
+			}
+
+			pinned := map[string]string{"jei": "4.15.0"}
+			err := pinImportedVersion(client, "demo", "1.0.0", pinned, "jei@4.16.0")
+
+			if tt.err != (err != nil) {
+				t.Fatalf("expected failure %v, got %v", tt.err, err)
+			}
+
+			if !reflect.DeepEqual(client.pins[2], tt.pins) {
+				t.Errorf("expected pins %v, got %v", tt.pins, client.pins[2])
+			}
+		})
+	}
+}
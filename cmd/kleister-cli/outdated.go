@@ -0,0 +1,220 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/kleister/kleister-go/kleister"
+)
+
+var (
+	// versionChunks splits version names into numeric and other chunks.
+	versionChunks = regexp.MustCompile(`\d+|[^\d]+`)
+
+	// minecraftPrefix matches version names prefixed by the Minecraft version
+	// like 1.12.2-14.23.5.2847 which would be parsed as pre-release.
+	minecraftPrefix = regexp.MustCompile(`^(1\.\d+(?:\.\d+)?)-(\d.*)$`)
+)
+
+// OutdatedVersion represents a pinned version with a newer version available.
+type OutdatedVersion struct {
+	Mod         string `json:"mod" xml:"mod"`
+	ModName     string `json:"mod_name" xml:"mod_name"`
+	Current     string `json:"current" xml:"current"`
+	CurrentName string `json:"current_name" xml:"current_name"`
+	Latest      string `json:"latest" xml:"latest"`
+	LatestName  string `json:"latest_name" xml:"latest_name"`
+}
+
+// FindOutdated compares the versions pinned by the build with all versions of
+// the related mods. Pre-releases are only suggested if the pinned version is a
+// pre-release as well, versions prefixed by another Minecraft version than the
+// pinned version or the build are never suggested.
+func FindOutdated(client kleister.ClientAPI, pack, build string) ([]*OutdatedVersion, error) {
+	target := ""
+
+	record, err := client.BuildGet(
+		pack,
+		build,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	minecraft, _, err := ResolveLoader(
+		client,
+		record,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if minecraft != nil {
+		target = minecraft.Version
+	}
+
+	records, err := client.BuildVersionList(
+		kleister.BuildVersionParams{
+			Pack:  pack,
+			Build: build,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := NewModResolver(client)
+	result := make([]*OutdatedVersion, 0)
+
+	for _, record := range records {
+		if record.Version == nil {
+			continue
+		}
+
+		mod, err := resolver.Mod(record.Version)
+
+		if err != nil {
+			return nil, err
+		}
+
+		versions, err := client.VersionList(
+			mod.Slug,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		current := record.Version
+		latest := current
+
+		compatible := minecraftVersion(versionName(current))
+
+		if compatible == "" {
+			compatible = target
+		}
+
+		for _, candidate := range versions {
+			if isPrerelease(versionName(candidate)) && !isPrerelease(versionName(current)) {
+				continue
+			}
+
+			if prefix := minecraftVersion(versionName(candidate)); prefix != "" && compatible != "" && prefix != compatible {
+				continue
+			}
+
+			if CompareVersions(versionName(candidate), versionName(latest)) > 0 {
+				latest = candidate
+			}
+		}
+
+		if latest.Slug == current.Slug {
+			continue
+		}
+
+		result = append(result, &OutdatedVersion{
+			Mod:         mod.Slug,
+			ModName:     mod.Name,
+			Current:     current.Slug,
+			CurrentName: versionName(current),
+			Latest:      latest.Slug,
+			LatestName:  versionName(latest),
+		})
+	}
+
+	return result, nil
+}
+
+// CompareVersions compares two version names, it returns -1, 0 or 1. Names
+// prefixed by the Minecraft version compare the remaining version, the prefix
+// only decides between equal versions. Names which are no valid
+// semantic versions get compared chunk by chunk, numeric chunks by their value.
+func CompareVersions(a, b string) int {
+	lprefix := minecraftPrefix.FindStringSubmatch(a)
+	rprefix := minecraftPrefix.FindStringSubmatch(b)
+
+	if lprefix != nil && rprefix != nil {
+		if res := CompareVersions(lprefix[2], rprefix[2]); res != 0 {
+			return res
+		}
+
+		return CompareVersions(lprefix[1], rprefix[1])
+	}
+
+	if lprefix != nil {
+		return CompareVersions(lprefix[2], b)
+	}
+
+	if rprefix != nil {
+		return CompareVersions(a, rprefix[2])
+	}
+
+	left, lerr := version.NewVersion(a)
+	right, rerr := version.NewVersion(b)
+
+	if lerr == nil && rerr == nil {
+		return left.Compare(right)
+	}
+
+	lchunks := versionChunks.FindAllString(a, -1)
+	rchunks := versionChunks.FindAllString(b, -1)
+
+	for i := 0; i < len(lchunks) && i < len(rchunks); i++ {
+		lnum, lerr := strconv.ParseInt(lchunks[i], 10, 64)
+		rnum, rerr := strconv.ParseInt(rchunks[i], 10, 64)
+
+		if lerr == nil && rerr == nil {
+			if res := compareInts(lnum, rnum); res != 0 {
+				return res
+			}
+
+			continue
+		}
+
+		if res := strings.Compare(lchunks[i], rchunks[i]); res != 0 {
+			return res
+		}
+	}
+
+	return compareInts(int64(len(lchunks)), int64(len(rchunks)))
+}
+
+// versionName returns the name of the version, it falls back to the slug.
+func versionName(record *kleister.Version) string {
+	if record.Name != "" {
+		return record.Name
+	}
+
+	return record.Slug
+}
+
+// minecraftVersion returns the Minecraft version prefixing the version name,
+// it's empty if the name isn't prefixed.
+func minecraftVersion(name string) string {
+	if matches := minecraftPrefix.FindStringSubmatch(name); matches != nil {
+		return matches[1]
+	}
+
+	return ""
+}
+
+// isPrerelease checks if the version name defines a pre-release, a Minecraft
+// version prefix is ignored.
+func isPrerelease(name string) bool {
+	if matches := minecraftPrefix.FindStringSubmatch(name); matches != nil {
+		name = matches[2]
+	}
+
+	parsed, err := version.NewVersion(name)
+
+	if err != nil {
+		return false
+	}
+
+	return parsed.Prerelease() != ""
+}
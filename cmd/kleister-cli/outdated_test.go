@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0-beta", "2.0.0", -1},
+		{"2.0.0-alpha", "2.0.0-beta", -1},
+		{"1.12.2", "1.12", 1},
+		{"1.12.0", "1.12", 0},
+		{"1.12.2-14.23.5.2847", "1.12.2-14.23.5.2768", 1},
+		{"1.12.2-14.23.5.2768", "1.12.2-14.23.5.2847", -1},
+		{"1.12.2-14.23.5.2847", "1.12.2-14.23.5.2847", 0},
+		{"1.12.2-4.16.0", "1.12.2-4.15.0", 1},
+		{"1.13.2-4.0.0", "1.12.2-9.0.0", -1},
+		{"1.13.2-4.0.0", "1.12.2-4.0.0", 1},
+		{"1.12.2-4.16.0", "1.12.2-4.16.0-beta", 1},
+		{"1.12.2-14.23.5.2847", "1.12.2", 1},
+		{"1.12.2-2.1.0", "2.0.0", 1},
+		{"2.0.0", "1.12.2-2.0.0", 0},
+		{"r2.1", "r2.10", -1},
+		{"HD_U_E3", "HD_U_E2", 1},
+		{"b1", "b1", 0},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q): expected %d, got %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestMinecraftVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"1.12.2-14.23.5.2847", "1.12.2"},
+		{"1.16-4.0.0", "1.16"},
+		{"4.16.0", ""},
+		{"HD_U_E3", ""},
+	}
+
+	for _, tt := range tests {
+		if got := minecraftVersion(tt.name); got != tt.want {
+			t.Errorf("minecraftVersion(%q): expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"1.0.0", false},
+		{"1.0.0-beta", true},
+		{"2.0.0-rc.1", true},
+		{"1.12.2-14.23.5.2847", false},
+		{"1.12.2-4.16.0", false},
+		{"1.12.2-4.16.0-beta", true},
+		{"HD_U_E3", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPrerelease(tt.name); got != tt.want {
+			t.Errorf("isPrerelease(%q): expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFindOutdated(t *testing.T) {
+	client := newTestClient()
+	client.minecraft = []*kleister.Minecraft{{ID: 1, Slug: "1.12.2", Version: "1.12.2"}}
+	client.packs = []*kleister.Pack{{ID: 1, Slug: "demo"}}
+	client.builds = []*kleister.Build{{ID: 2, PackID: 1, Slug: "1.0.0", MinecraftID: null.IntFrom(1)}}
+	client.mods = []*kleister.Mod{{ID: 3, Slug: "jei"}, {ID: 4, Slug: "optifine"}}
+	client.versions = []*kleister.Version{
+		{ID: 10, ModID: 3, Slug: "1.12.2-4.15.0", Name: "1.12.2-4.15.0"},
+		{ID: 11, ModID: 3, Slug: "1.12.2-4.16.0", Name: "1.12.2-4.16.0"},
+		{ID: 12, ModID: 3, Slug: "1.13.2-5.0.0", Name: "1.13.2-5.0.0"},
+		{ID: 13, ModID: 3, Slug: "1.12.2-4.17.0-beta", Name: "1.12.2-4.17.0-beta"},
+		{ID: 20, ModID: 4, Slug: "2.0.0", Name: "2.0.0"},
+		{ID: 21, ModID: 4, Slug: "1.13.2-3.0.0", Name: "1.13.2-3.0.0"},
+		{ID: 22, ModID: 4, Slug: "1.12.2-2.1.0", Name: "1.12.2-2.1.0"},
+	}
+	client.pins[2] = []int64{10, 20}
+
+	records, err := FindOutdated(client, "demo", "1.0.0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string, len(records))
+
+	for _, record := range records {
+		got[record.Mod] = record.Latest
+	}
+
+	want := map[string]string{
+		"jei":      "1.12.2-4.16.0",
+		"optifine": "1.12.2-2.1.0",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,685 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+	"gopkg.in/urfave/cli.v2"
+	"gopkg.in/yaml.v2"
+)
+
+// outputFormats defines the supported values of the global output flag.
+var outputFormats = []string{
+	"text",
+	"table",
+	"json",
+	"jsonl",
+	"yaml",
+	"csv",
+	"xml",
+}
+
+// OutputList renders a list of records with the selected output format.
+func OutputList(c *cli.Context, records interface{}) error {
+	rows, err := FilterRecords(reflect.ValueOf(records), c.String("filter"))
+
+	if err != nil {
+		return err
+	}
+
+	if err := SortRecords(rows, c.String("sort-by")); err != nil {
+		return err
+	}
+
+	rows, err = LimitRecords(c, rows)
+
+	if err != nil {
+		return err
+	}
+
+	records = rows.Interface()
+
+	switch outputFormat(c) {
+	case "json":
+		return writeJSON(os.Stdout, records)
+	case "jsonl":
+		for i := 0; i < rows.Len(); i++ {
+			res, err := json.Marshal(rows.Index(i).Interface())
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stdout, "%s\n", res)
+		}
+
+		return nil
+	case "yaml":
+		return writeYAML(os.Stdout, records)
+	case "xml":
+		return writeXML(os.Stdout, records)
+	case "csv":
+		columns, err := selectColumns(c, rows)
+
+		if err != nil {
+			return err
+		}
+
+		return writeCSV(os.Stdout, rows, columns)
+	case "table":
+		columns, err := selectColumns(c, rows)
+
+		if err != nil {
+			return err
+		}
+
+		if rows.Len() == 0 {
+			fmt.Fprintf(os.Stderr, "empty result\n")
+			return nil
+		}
+
+		return writeTable(os.Stdout, rows, columns)
+	case "text":
+		if rows.Len() == 0 {
+			fmt.Fprintf(os.Stderr, "empty result\n")
+			return nil
+		}
+
+		tmpl, err := outputTemplate(c)
+
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < rows.Len(); i++ {
+			err := tmpl.Execute(os.Stdout, rows.Index(i).Interface())
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("invalid output type, can be %s", strings.Join(outputFormats, ", "))
+}
+
+// OutputRecord renders a single record with the selected output format.
+func OutputRecord(c *cli.Context, record interface{}) error {
+	rows := reflect.Append(
+		reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(record)), 0, 1),
+		reflect.ValueOf(record),
+	)
+
+	switch outputFormat(c) {
+	case "json":
+		return writeJSON(os.Stdout, record)
+	case "jsonl":
+		res, err := json.Marshal(record)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stdout, "%s\n", res)
+		return nil
+	case "yaml":
+		return writeYAML(os.Stdout, record)
+	case "xml":
+		return writeXML(os.Stdout, record)
+	case "csv":
+		columns, err := selectColumns(c, rows)
+
+		if err != nil {
+			return err
+		}
+
+		return writeCSV(os.Stdout, rows, columns)
+	case "table":
+		columns, err := selectColumns(c, rows)
+
+		if err != nil {
+			return err
+		}
+
+		return writeTable(os.Stdout, rows, columns)
+	case "text":
+		tmpl, err := outputTemplate(c)
+
+		if err != nil {
+			return err
+		}
+
+		return tmpl.Execute(os.Stdout, record)
+	}
+
+	return fmt.Errorf("invalid output type, can be %s", strings.Join(outputFormats, ", "))
+}
+
+// outputFormat returns the normalized value of the output flag, the legacy
+// flags of the sub-commands take precedence over the global flag.
+func outputFormat(c *cli.Context) string {
+	for _, legacy := range []string{"json", "xml"} {
+		if c.Bool(legacy) {
+			return legacy
+		}
+	}
+
+	for _, ctx := range c.Lineage() {
+		if val := strings.ToLower(ctx.String("output")); val != "" {
+			return val
+		}
+	}
+
+	return "text"
+}
+
+// LegacyOutputFlags appends the deprecated --json, --xml and --output flags to
+// all sub-commands, they are hidden and only kept for existing scripts.
+func LegacyOutputFlags(commands []*cli.Command) {
+	for _, cmd := range commands {
+		if len(cmd.Subcommands) > 0 {
+			LegacyOutputFlags(cmd.Subcommands)
+			continue
+		}
+
+		cmd.Flags = append(
+			cmd.Flags,
+			&cli.StringFlag{
+				Name:   "output",
+				Value:  "",
+				Usage:  "deprecated, use the global output flag",
+				Hidden: true,
+			},
+			&cli.BoolFlag{
+				Name:   "json",
+				Value:  false,
+				Usage:  "deprecated, use the global output flag",
+				Hidden: true,
+			},
+			&cli.BoolFlag{
+				Name:   "xml",
+				Value:  false,
+				Usage:  "deprecated, use the global output flag",
+				Hidden: true,
+			},
+		)
+	}
+}
+
+// outputTemplate parses the custom format of the command.
+func outputTemplate(c *cli.Context) (*template.Template, error) {
+	return template.New(
+		"_",
+	).Funcs(
+		globalFuncMap,
+	).Funcs(
+		sprigFuncMap,
+	).Parse(
+		fmt.Sprintf("%s\n", c.String("format")),
+	)
+}
+
+// writeJSON writes the value as indented JSON.
+func writeJSON(w io.Writer, value interface{}) error {
+	res, err := json.MarshalIndent(value, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s\n", res)
+	return nil
+}
+
+// writeXML writes the value as indented XML.
+func writeXML(w io.Writer, value interface{}) error {
+	res, err := xml.MarshalIndent(value, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s\n", res)
+	return nil
+}
+
+// writeYAML writes the value as YAML, it takes the JSON representation to
+// get the same field names for both formats.
+func writeYAML(w io.Writer, value interface{}) error {
+	res, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	var generic interface{}
+
+	if err := json.Unmarshal(res, &generic); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(generic)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// writeCSV writes the rows as CSV including a header.
+func writeCSV(w io.Writer, rows reflect.Value, columns []string) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for i := 0; i < rows.Len(); i++ {
+		if err := writer.Write(outputRow(rows.Index(i), columns)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTable writes the rows as aligned table including a header.
+func writeTable(w io.Writer, rows reflect.Value, columns []string) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := make([]string, len(columns))
+
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+
+	for i := 0; i < rows.Len(); i++ {
+		fmt.Fprintln(writer, strings.Join(outputRow(rows.Index(i), columns), "\t"))
+	}
+
+	return writer.Flush()
+}
+
+// selectColumns returns the columns requested by the columns flag or the
+// default columns of the records.
+func selectColumns(c *cli.Context, rows reflect.Value) ([]string, error) {
+	if c.String("columns") == "" {
+		return outputColumns(rows), nil
+	}
+
+	result := []string{}
+
+	for _, name := range strings.Split(c.String("columns"), ",") {
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		column, ok := ResolveField(rows.Type().Elem(), name)
+
+		if !ok {
+			return nil, fmt.Errorf("unknown column %s", name)
+		}
+
+		result = append(result, column)
+	}
+
+	return result, nil
+}
+
+// SortRecords sorts the rows by the value of a field, a leading dash sorts in
+// descending order. An empty field keeps the order of the server.
+func SortRecords(rows reflect.Value, field string) error {
+	if field == "" {
+		return nil
+	}
+
+	descending := strings.HasPrefix(field, "-")
+	path, ok := ResolveField(rows.Type().Elem(), strings.TrimPrefix(field, "-"))
+
+	if !ok {
+		return fmt.Errorf("unknown sort field %s", strings.TrimPrefix(field, "-"))
+	}
+
+	values := make([]reflect.Value, rows.Len())
+
+	for i := range values {
+		values[i] = OutputField(rows.Index(i), path)
+	}
+
+	swap := reflect.Swapper(rows.Interface())
+
+	sort.Stable(recordSorter{
+		values: values,
+		swap:   swap,
+		desc:   descending,
+	})
+
+	return nil
+}
+
+// recordSorter sorts records by a list of precomputed field values.
+type recordSorter struct {
+	values []reflect.Value
+	swap   func(i, j int)
+	desc   bool
+}
+
+func (s recordSorter) Len() int {
+	return len(s.values)
+}
+
+func (s recordSorter) Less(i, j int) bool {
+	if s.desc {
+		return compareValues(s.values[j], s.values[i]) < 0
+	}
+
+	return compareValues(s.values[i], s.values[j]) < 0
+}
+
+func (s recordSorter) Swap(i, j int) {
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+	s.swap(i, j)
+}
+
+// compareValues compares two field values, missing values are sorted first.
+func compareValues(a, b reflect.Value) int {
+	if !a.IsValid() || !b.IsValid() {
+		switch {
+		case a.IsValid():
+			return 1
+		case b.IsValid():
+			return -1
+		}
+
+		return 0
+	}
+
+	switch x := a.Interface().(type) {
+	case time.Time:
+		y := b.Interface().(time.Time)
+
+		switch {
+		case x.Before(y):
+			return -1
+		case x.After(y):
+			return 1
+		}
+
+		return 0
+	case null.Int:
+		y := b.Interface().(null.Int)
+
+		if !x.Valid || !y.Valid {
+			return compareValues(validValue(x.Valid), validValue(y.Valid))
+		}
+
+		return compareInts(x.Int64, y.Int64)
+	}
+
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(strings.ToLower(a.String()), strings.ToLower(b.String()))
+	case reflect.Int, reflect.Int64:
+		return compareInts(a.Int(), b.Int())
+	case reflect.Float64:
+		switch {
+		case a.Float() < b.Float():
+			return -1
+		case a.Float() > b.Float():
+			return 1
+		}
+	case reflect.Bool:
+		switch {
+		case !a.Bool() && b.Bool():
+			return -1
+		case a.Bool() && !b.Bool():
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// compareInts compares two integers.
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// validValue maps the validity of a nullable value to a comparable value.
+func validValue(valid bool) reflect.Value {
+	if valid {
+		return reflect.ValueOf(true)
+	}
+
+	return reflect.Value{}
+}
+
+// ResolveField maps a user provided field name like updated or user.slug to
+// the field path of the record type. Names are matched case insensitive,
+// ignoring dashes and underscores, and an At or ID suffix can be omitted.
+func ResolveField(t reflect.Type, name string) (string, bool) {
+	result := []string{}
+	parts := strings.Split(name, ".")
+
+	for i, part := range parts {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct {
+			return "", false
+		}
+
+		field, ok := matchField(t, part, i == len(parts)-1)
+
+		if !ok {
+			return "", false
+		}
+
+		result = append(result, field.Name)
+		t = field.Type
+	}
+
+	return strings.Join(result, "."), true
+}
+
+// matchField searches a visible field of the struct matching the name, the
+// last part of a path must match a scalar field.
+func matchField(t reflect.Type, name string, scalar bool) (reflect.StructField, bool) {
+	normalize := strings.NewReplacer("-", "", "_", "")
+	needle := strings.ToLower(normalize.Replace(name))
+
+	for _, suffix := range []string{"", "at", "id"} {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			if field.Anonymous && field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+				if embedded, ok := matchField(field.Type.Elem(), name, scalar); ok {
+					return embedded, true
+				}
+			}
+
+			if !outputVisible(field) || scalar != outputScalar(field.Type) {
+				continue
+			}
+
+			if strings.ToLower(field.Name) == needle+suffix {
+				return field, true
+			}
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// outputColumns derives the columns from the scalar fields of the first row,
+// fields of embedded records are used as they are.
+// Assignment records without an own ID include the fields of the related
+// records, prefixed by the field name like User.Slug.
+func outputColumns(rows reflect.Value) []string {
+	result := []string{}
+
+	if rows.Len() == 0 {
+		return result
+	}
+
+	record := reflect.Indirect(rows.Index(0))
+
+	if record.Kind() != reflect.Struct {
+		return []string{"Value"}
+	}
+
+	flatten := !record.FieldByName("ID").IsValid()
+
+	for i := 0; i < record.NumField(); i++ {
+		field := record.Type().Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			for j := 0; j < field.Type.Elem().NumField(); j++ {
+				embedded := field.Type.Elem().Field(j)
+
+				if outputVisible(embedded) && outputScalar(embedded.Type) {
+					result = append(result, embedded.Name)
+				}
+			}
+
+			continue
+		}
+
+		if !outputVisible(field) {
+			continue
+		}
+
+		if outputScalar(field.Type) {
+			result = append(result, field.Name)
+			continue
+		}
+
+		if !flatten || field.Type.Kind() != reflect.Ptr || field.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		if record.Field(i).IsNil() {
+			continue
+		}
+
+		for j := 0; j < field.Type.Elem().NumField(); j++ {
+			nested := field.Type.Elem().Field(j)
+
+			if outputVisible(nested) && outputScalar(nested.Type) {
+				result = append(result, field.Name+"."+nested.Name)
+			}
+		}
+	}
+
+	return result
+}
+
+// outputVisible checks if a field should be part of tabular output.
+func outputVisible(field reflect.StructField) bool {
+	if field.PkgPath != "" || field.Name == "Password" {
+		return false
+	}
+
+	return field.Tag.Get("json") != "-"
+}
+
+// outputScalar checks if a type can be rendered within a single cell.
+func outputScalar(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(null.Int{}):
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// outputRow renders the values of the columns for a single record.
+func outputRow(record reflect.Value, columns []string) []string {
+	result := make([]string, len(columns))
+
+	for i, column := range columns {
+		result[i] = outputCell(OutputField(record, column))
+	}
+
+	return result
+}
+
+// OutputField resolves a field path like User.Slug on a record, it returns an
+// invalid value if the field or a related record doesn't exist.
+func OutputField(record reflect.Value, path string) reflect.Value {
+	value := record
+
+	for _, name := range strings.Split(path, ".") {
+		value = reflect.Indirect(value)
+
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+
+		value = value.FieldByName(name)
+
+		if !value.IsValid() {
+			return value
+		}
+	}
+
+	return value
+}
+
+// outputCell formats a single value for tabular output.
+func outputCell(value reflect.Value) string {
+	if !value.IsValid() {
+		return ""
+	}
+
+	switch val := value.Interface().(type) {
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+
+		return val.Format(time.RFC3339)
+	case null.Int:
+		if !val.Valid {
+			return ""
+		}
+
+		return strconv.FormatInt(val.Int64, 10)
+	}
+
+	return fmt.Sprintf("%v", value.Interface())
+}
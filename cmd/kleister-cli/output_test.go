@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/guregu/null.v3"
+	"gopkg.in/urfave/cli.v2"
+)
+
+type outputRecord struct {
+	ID      int64    `json:"id"`
+	Slug    string   `json:"slug"`
+	Latest  null.Int `json:"latest"`
+	Private bool     `json:"private"`
+	Secret  string   `json:"-"`
+}
+
+var outputRecords = []*outputRecord{
+	{ID: 1, Slug: "demo", Latest: null.IntFrom(3)},
+	{ID: 2, Slug: "with, comma", Private: true},
+}
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"kc", "list"}, "text"},
+		{[]string{"kc", "--output", "YAML", "list"}, "yaml"},
+		{[]string{"kc", "list", "--output", "json"}, "json"},
+		{[]string{"kc", "--output", "csv", "list"}, "csv"},
+		{[]string{"kc", "--output", "csv", "list", "--xml"}, "xml"},
+		{[]string{"kc", "list", "--json"}, "json"},
+	}
+
+	for _, tt := range tests {
+		got := ""
+
+		app := &cli.App{
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "output",
+					Value: "text",
+				},
+			},
+			Commands: []*cli.Command{
+				{
+					Name: "list",
+					Action: func(c *cli.Context) error {
+						got = outputFormat(c)
+						return nil
+					},
+				},
+			},
+		}
+
+		LegacyOutputFlags(app.Commands)
+
+		if err := app.Run(tt.args); err != nil {
+			t.Fatalf("%v: %s", tt.args, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.args, tt.want, got)
+		}
+	}
+}
+
+func TestOutputColumns(t *testing.T) {
+	got := outputColumns(reflect.ValueOf(outputRecords))
+	want := []string{"ID", "Slug", "Latest", "Private"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestOutputColumnsEmbedded(t *testing.T) {
+	type details struct {
+		*outputRecord
+		Checksum string
+	}
+
+	rows := reflect.ValueOf([]*details{{outputRecord: outputRecords[0], Checksum: "abc"}})
+	want := []string{"ID", "Slug", "Latest", "Private", "Checksum"}
+
+	if got := outputColumns(rows); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got, ok := ResolveField(rows.Type().Elem(), "slug"); !ok || got != "Slug" {
+		t.Errorf("expected Slug, got %q", got)
+	}
+}
+
+func TestResolveField(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		name  string
+		want  string
+		ok    bool
+	}{
+		{outputRecord{}, "slug", "Slug", true},
+		{outputRecord{}, "SLUG", "Slug", true},
+		{outputRecord{}, "id", "ID", true},
+		{outputRecord{}, "secret", "", false},
+		{outputRecord{}, "unknown", "", false},
+		{kleister.Pack{}, "updated", "UpdatedAt", true},
+		{kleister.Pack{}, "created_at", "CreatedAt", true},
+		{kleister.Build{}, "min-java", "MinJava", true},
+		{kleister.Build{}, "minecraft", "MinecraftID", true},
+		{kleister.UserPack{}, "user.slug", "User.Slug", true},
+		{kleister.UserPack{}, "user", "", false},
+		{kleister.UserPack{}, "perm.slug", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ResolveField(reflect.PtrTo(reflect.TypeOf(tt.value)), tt.name)
+
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%T %s: expected %q %v, got %q %v", tt.value, tt.name, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestSortRecords(t *testing.T) {
+	tests := []struct {
+		field string
+		want  []string
+	}{
+		{"", []string{"b", "C", "a", "d"}},
+		{"slug", []string{"a", "b", "C", "d"}},
+		{"-slug", []string{"d", "C", "b", "a"}},
+		{"latest", []string{"C", "d", "b", "a"}},
+		{"-latest", []string{"a", "b", "C", "d"}},
+		{"private", []string{"b", "a", "C", "d"}},
+		{"-id", []string{"d", "C", "b", "a"}},
+	}
+
+	for _, tt := range tests {
+		rows := []*outputRecord{
+			{ID: 2, Slug: "b", Latest: null.IntFrom(5)},
+			{ID: 3, Slug: "C", Private: true},
+			{ID: 1, Slug: "a", Latest: null.IntFrom(9)},
+			{ID: 4, Slug: "d", Private: true},
+		}
+
+		if err := SortRecords(reflect.ValueOf(rows), tt.field); err != nil {
+			t.Errorf("%q: unexpected error %s", tt.field, err)
+			continue
+		}
+
+		got := []string{}
+
+		for _, record := range rows {
+			got = append(got, record.Slug)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.field, tt.want, got)
+		}
+	}
+
+	if err := SortRecords(reflect.ValueOf(outputRecords), "unknown"); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+}
+
+func TestOutputCell(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"demo", "demo"},
+		{int64(42), "42"},
+		{true, "true"},
+		{null.IntFrom(7), "7"},
+		{null.Int{}, ""},
+		{time.Time{}, ""},
+		{time.Date(2019, 5, 7, 8, 0, 0, 0, time.UTC), "2019-05-07T08:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		if got := outputCell(reflect.ValueOf(tt.value)); got != tt.want {
+			t.Errorf("%#v: expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+
+	if got := outputCell(reflect.Value{}); got != "" {
+		t.Errorf("invalid value: expected empty cell, got %q", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := writeCSV(buf, reflect.ValueOf(outputRecords), []string{"ID", "Slug", "Latest"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "ID,Slug,Latest\n1,demo,3\n2,\"with, comma\",\n"
+
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := writeTable(buf, reflect.ValueOf(outputRecords), []string{"ID", "Slug", "Private"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "ID  SLUG         PRIVATE\n1   demo         false\n2   with, comma  true\n"
+
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := writeYAML(buf, outputRecords[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "id: 1\nlatest: 3\nprivate: false\nslug: demo\n"
+
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
@@ -1,13 +1,11 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
-	"text/template"
 
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/guregu/null.v3"
@@ -76,15 +74,30 @@ func Pack() *cli.Command {
 						Value: tmplPackList,
 						Usage: "Custom output format",
 					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "Comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "Sort by a field, prefix with a dash for descending order",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Value: "",
+						Usage: "Filter by expression on the record fields",
+					},
 					&cli.BoolFlag{
-						Name:  "json",
+						Name:  "first",
 						Value: false,
-						Usage: "Print in JSON format",
+						Usage: "Return only first record",
 					},
 					&cli.BoolFlag{
-						Name:  "xml",
+						Name:  "last",
 						Value: false,
-						Usage: "Print in XML format",
+						Usage: "Return only last record",
 					},
 				},
 				Action: func(c *cli.Context) error {
@@ -106,16 +119,6 @@ func Pack() *cli.Command {
 						Value: tmplPackShow,
 						Usage: "Custom output format",
 					},
-					&cli.BoolFlag{
-						Name:  "json",
-						Value: false,
-						Usage: "Print in JSON format",
-					},
-					&cli.BoolFlag{
-						Name:  "xml",
-						Value: false,
-						Usage: "Print in XML format",
-					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, PackShow)
@@ -302,6 +305,146 @@ func Pack() *cli.Command {
 					return Handle(c, PackCreate)
 				},
 			},
+			{
+				Name:      "apply",
+				Usage:     "Apply a pack manifest",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "file",
+						Aliases: []string{"f"},
+						Value:   "",
+						Usage:   "Path to the YAML or TOML manifest",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, PackApply)
+				},
+			},
+			{
+				Name:      "export",
+				Usage:     "Export a pack manifest",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "Pack ID or slug to export",
+					},
+					&cli.StringFlag{
+						Name:    "file",
+						Aliases: []string{"f"},
+						Value:   "",
+						Usage:   "Path to the YAML or TOML manifest, defaults to stdout",
+					},
+					&cli.BoolFlag{
+						Name:  "sidecar",
+						Value: false,
+						Usage: "Write assets as sidecar files instead of inline",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, PackExport)
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "Import a pack from a modpack archive",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "file",
+						Aliases: []string{"f"},
+						Value:   "",
+						Usage:   "Path to the modpack archive",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "curseforge",
+						Usage: "Format of the archive, can be curseforge",
+					},
+					&cli.StringFlag{
+						Name:  "pack",
+						Value: "",
+						Usage: "Pack slug to import into, defaults to the modpack name",
+					},
+					&cli.StringFlag{
+						Name:  "build",
+						Value: "",
+						Usage: "Build slug to create, defaults to the modpack version",
+					},
+					&cli.StringFlag{
+						Name:  "mapping",
+						Value: "",
+						Usage: "File to record the CurseForge project and file ids",
+					},
+					&cli.StringFlag{
+						Name:  "download-url",
+						Value: curseDownloadURL,
+						Usage: "URL to download CurseForge files, {project} and {file} get replaced",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Value: false,
+						Usage: "Import files even if they are already uploaded",
+					},
+					&cli.BoolFlag{
+						Name:  "skip-overrides",
+						Value: false,
+						Usage: "Import without override files outside of the mods folder",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, PackImport)
+				},
+			},
+			{
+				Name:        "release",
+				Usage:       "Promote a build to latest and optionally recommended",
+				Description: "The replaced pointers are only recorded within the local releases.yml next to the config, a rollback is only possible from the same machine.",
+				ArgsUsage:   " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "Pack ID or slug to release",
+					},
+					&cli.StringFlag{
+						Name:  "build",
+						Value: "",
+						Usage: "Build ID or slug to promote",
+					},
+					&cli.BoolFlag{
+						Name:  "recommended",
+						Value: false,
+						Usage: "Mark build recommended as well",
+					},
+					&cli.BoolFlag{
+						Name:  "publish",
+						Value: false,
+						Usage: "Publish the build if it's hidden",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, PackRelease)
+				},
+			},
+			{
+				Name:        "rollback",
+				Usage:       "Restore the pointers replaced by the last release",
+				Description: "The release gets read from the local releases.yml next to the config, releases made on other machines can't be rolled back.",
+				ArgsUsage:   " ",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "id, i",
+						Value: "",
+						Usage: "Pack ID or slug to roll back",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return Handle(c, PackRollback)
+				},
+			},
 			{
 				Name:  "client",
 				Usage: "Client assignments",
@@ -322,15 +465,30 @@ func Pack() *cli.Command {
 								Value: tmplPackClientList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -400,15 +558,30 @@ func Pack() *cli.Command {
 								Value: tmplPackUserList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -508,15 +681,30 @@ func Pack() *cli.Command {
 								Value: tmplPackTeamList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -608,60 +796,7 @@ func PackList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // PackShow provides the sub-command to show pack details.
@@ -674,47 +809,7 @@ func PackShow(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	return tmpl.Execute(os.Stdout, record)
+	return OutputRecord(c, record)
 }
 
 // PackDelete provides the sub-command to delete a pack.
@@ -1039,74 +1134,166 @@ func PackCreate(c *cli.Context, client kleister.ClientAPI) error {
 	return nil
 }
 
-// PackClientList provides the sub-command to list packs of the pack.
-func PackClientList(c *cli.Context, client kleister.ClientAPI) error {
-	records, err := client.PackClientList(
-		kleister.PackClientParams{
-			Pack: GetIdentifierParam(c),
-		},
+// PackApply provides the sub-command to apply a pack manifest.
+func PackApply(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("file") == "" {
+		return fmt.Errorf("you must provide a manifest file")
+	}
+
+	manifest, err := LoadManifest(
+		c.String("file"),
 	)
 
 	if err != nil {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
+	if err := ApplyManifest(client, manifest); err != nil {
+		return err
 	}
 
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
+	fmt.Fprintf(os.Stderr, "Successfully applied\n")
+	return nil
+}
+
+// PackExport provides the sub-command to export a pack manifest.
+func PackExport(c *cli.Context, client kleister.ClientAPI) error {
+	assets := ""
 
-		if err != nil {
-			return err
+	if c.Bool("sidecar") {
+		assets = "."
+
+		if c.String("file") != "" {
+			assets = filepath.Dir(c.String("file"))
 		}
+	}
+
+	manifest, err := ExportManifest(
+		client,
+		GetIdentifierParam(c),
+		assets,
+	)
+
+	if err != nil {
+		return err
+	}
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
+	if err := WriteManifest(c.String("file"), manifest); err != nil {
+		return err
 	}
 
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
+	if c.String("file") != "" {
+		fmt.Fprintf(os.Stderr, "Successfully exported\n")
+	}
 
-		if err != nil {
-			return err
-		}
+	return nil
+}
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
+// PackImport provides the sub-command to import a pack from a modpack archive.
+func PackImport(c *cli.Context, client kleister.ClientAPI) error {
+	if c.String("file") == "" {
+		return fmt.Errorf("you must provide a modpack archive")
 	}
 
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
+	if c.String("format") != "curseforge" {
+		return fmt.Errorf("invalid import format, can be curseforge")
 	}
 
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
+	mapping, err := LoadCurseMapping(
+		c.String("mapping"),
 	)
 
 	if err != nil {
 		return err
 	}
 
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
+	build, err := ImportCurseForge(
+		client,
+		&CurseImport{
+			File:     c.String("file"),
+			Pack:     c.String("pack"),
+			Build:    c.String("build"),
+			Download: c.String("download-url"),
+			Mapping:  mapping,
+			Force:    c.Bool("force"),
 
-		if err != nil {
+			SkipOverrides: c.Bool("skip-overrides"),
+		},
+	)
+
+	if c.String("mapping") != "" {
+		if err := mapping.Write(c.String("mapping")); err != nil {
 			return err
 		}
 	}
 
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Successfully imported build %s\n", build.Slug)
+	return nil
+}
+
+// PackRelease provides the sub-command to promote a build of a pack.
+func PackRelease(c *cli.Context, client kleister.ClientAPI) error {
+	server, _, _ := ResolveCredentials(c)
+
+	release, err := ReleaseBuild(
+		client,
+		server,
+		GetIdentifierParam(c),
+		GetBuildParam(c),
+		c.Bool("recommended"),
+		c.Bool("publish"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if release.Recommended {
+		fmt.Fprintf(os.Stderr, "Successfully released build %s as latest and recommended\n", release.Slug)
+	} else {
+		fmt.Fprintf(os.Stderr, "Successfully released build %s as latest\n", release.Slug)
+	}
+
 	return nil
 }
 
+// PackRollback provides the sub-command to roll back the last release of a pack.
+func PackRollback(c *cli.Context, client kleister.ClientAPI) error {
+	server, _, _ := ResolveCredentials(c)
+
+	release, err := RollbackRelease(
+		client,
+		server,
+		GetIdentifierParam(c),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Successfully rolled back release of build %s\n", release.Slug)
+	return nil
+}
+
+// PackClientList provides the sub-command to list packs of the pack.
+func PackClientList(c *cli.Context, client kleister.ClientAPI) error {
+	records, err := client.PackClientList(
+		kleister.PackClientParams{
+			Pack: GetIdentifierParam(c),
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return OutputList(c, records)
+}
+
 // PackClientAppend provides the sub-command to append a client to the pack.
 func PackClientAppend(c *cli.Context, client kleister.ClientAPI) error {
 	err := client.PackClientAppend(
@@ -1153,60 +1340,7 @@ func PackUserList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // PackUserAppend provides the sub-command to append a user to the pack.
@@ -1274,60 +1408,7 @@ func PackTeamList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // PackTeamAppend provides the sub-command to append a team to the pack.
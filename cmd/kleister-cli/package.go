@@ -0,0 +1,489 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+// PackageIndex represents the JSON index of a packaged build.
+type PackageIndex struct {
+	Pack      string          `json:"pack"`
+	Build     string          `json:"build"`
+	Name      string          `json:"name"`
+	Side      string          `json:"side,omitempty"`
+	Artifact  string          `json:"artifact,omitempty"`
+	Minecraft string          `json:"minecraft,omitempty"`
+	Forge     string          `json:"forge,omitempty"`
+	MinJava   string          `json:"min_java,omitempty"`
+	MinMemory string          `json:"min_memory,omitempty"`
+	Mods      []*PackageEntry `json:"mods"`
+}
+
+// PackageEntry represents a single version file within a packaged build.
+type PackageEntry struct {
+	Mod     string `json:"mod"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Side    string `json:"side,omitempty"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	MD5     string `json:"md5"`
+	SHA256  string `json:"sha256"`
+}
+
+// BuildFile represents a version pinned within a build together with its mod.
+type BuildFile struct {
+	Mod     *kleister.Mod
+	Version *kleister.Version
+
+	// Local defines the path of the downloaded version file.
+	Local string
+}
+
+// Path returns the path of the version file within a mod repository.
+func (f *BuildFile) Path() string {
+	return path.Join(
+		"mods",
+		f.Mod.Slug,
+		fmt.Sprintf("%s-%s.zip", f.Mod.Slug, f.Version.Slug),
+	)
+}
+
+// JarPath returns the path of a plain mod jar within the mods folder.
+func (f *BuildFile) JarPath() string {
+	return path.Join(
+		"mods",
+		fmt.Sprintf("%s-%s.jar", f.Mod.Slug, f.Version.Slug),
+	)
+}
+
+// ListBuildFiles fetches the versions of a build and resolves their mods, the
+// result is sorted by the mod slug.
+func ListBuildFiles(client kleister.ClientAPI, resolver *ModResolver, pack, build string) ([]*BuildFile, error) {
+	records, err := client.BuildVersionList(
+		kleister.BuildVersionParams{
+			Pack:  pack,
+			Build: build,
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*BuildFile, 0, len(records))
+
+	for _, record := range records {
+		if record.Version == nil {
+			continue
+		}
+
+		mod, err := resolver.Mod(record.Version)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &BuildFile{
+			Mod:     mod,
+			Version: record.Version,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Mod.Slug < result[j].Mod.Slug
+	})
+
+	return result, nil
+}
+
+// PackageBuild downloads all version files of a build into the standard mod
+// repository layout below the output directory, plain jars get wrapped into a
+// zip with a mods folder, and writes an index.json describing the build. If a
+// side is given only the mods used on that side are included, the index is
+// named after the side and a ready to use archive gets written, the server
+// archive includes start scripts.
+func PackageBuild(client kleister.ClientAPI, pack, id, out, side string) (*PackageIndex, error) {
+	record, err := client.PackGet(
+		pack,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	build, err := client.BuildGet(
+		record.Slug,
+		id,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	minecraft, forge, err := ResolveLoader(
+		client,
+		build,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ListBuildFiles(
+		client,
+		NewModResolver(client),
+		record.Slug,
+		build.Slug,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PackageIndex{
+		Pack:      record.Slug,
+		Build:     build.Slug,
+		Name:      build.Name,
+		Side:      side,
+		MinJava:   build.MinJava,
+		MinMemory: build.MinMemory,
+		Mods:      make([]*PackageEntry, 0, len(files)),
+	}
+
+	if minecraft != nil {
+		result.Minecraft = minecraft.Slug
+	}
+
+	if forge != nil {
+		result.Forge = forge.Slug
+	}
+
+	included := make([]*BuildFile, 0, len(files))
+
+	for _, file := range files {
+		if !SupportsSide(file.Mod, side) {
+			fmt.Fprintf(os.Stderr, "skipped %s@%s, %s only\n", file.Mod.Slug, file.Version.Slug, file.Mod.Side)
+			continue
+		}
+
+		file.Local = filepath.Join(out, filepath.FromSlash(file.Path()))
+
+		checksum, size, err := packageVersionFile(
+			file,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to package %s@%s. %s", file.Mod.Slug, file.Version.Slug, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "packaged %s@%s\n", file.Mod.Slug, file.Version.Slug)
+
+		included = append(included, file)
+
+		result.Mods = append(result.Mods, &PackageEntry{
+			Mod:     file.Mod.Slug,
+			Name:    file.Mod.Name,
+			Version: file.Version.Slug,
+			Side:    file.Mod.Side,
+			Path:    file.Path(),
+			Size:    size,
+			MD5:     checksum.MD5,
+			SHA256:  checksum.SHA256,
+		})
+	}
+
+	index := "index.json"
+
+	if side != "" {
+		index = fmt.Sprintf("index-%s.json", side)
+		result.Artifact = fmt.Sprintf("%s-%s-%s.zip", record.Slug, build.Slug, side)
+
+		var scripts map[string]string
+
+		if side == "server" {
+			launch, err := NewServerLaunch(
+				record,
+				build,
+				minecraft,
+				forge,
+			)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if scripts, err = launch.Scripts(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := writeSideArtifact(filepath.Join(out, result.Artifact), included, scripts); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := json.MarshalIndent(result, "", "  ")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode index. %s", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(out, index), append(content, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write index")
+	}
+
+	return result, nil
+}
+
+// writeSideArtifact writes an archive with the mods folder of one side and
+// the scripts, which are marked as executable.
+func writeSideArtifact(out string, files []*BuildFile, scripts map[string]string) error {
+	file, err := os.Create(out)
+
+	if err != nil {
+		return fmt.Errorf("failed to create %s", out)
+	}
+
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	for _, row := range files {
+		if err := addVersionFile(archive, "", row); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(scripts))
+
+	for name := range scripts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		header := &zip.FileHeader{
+			Name:   name,
+			Method: zip.Deflate,
+		}
+
+		header.SetMode(0755)
+		writer, err := archive.CreateHeader(header)
+
+		if err != nil {
+			return fmt.Errorf("failed to add %s", name)
+		}
+
+		if _, err := writer.Write([]byte(scripts[name])); err != nil {
+			return fmt.Errorf("failed to add %s", name)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write %s", out)
+	}
+
+	return nil
+}
+
+// packageVersionFile downloads the file of a version to its local path within
+// the repository. Plain mod jars get wrapped into a zip with a mods folder,
+// bundles are already provided like that.
+func packageVersionFile(file *BuildFile) (*Checksum, int64, error) {
+	if !packagedVersionFile(file) {
+		download := file.Local + ".download"
+		defer os.Remove(download)
+
+		if _, _, err := FetchVersionFile(file.Version, download); err != nil {
+			return nil, 0, err
+		}
+
+		bundle := false
+
+		if archive, err := zip.OpenReader(download); err == nil {
+			bundle = isModBundle(archive.File)
+			archive.Close()
+		}
+
+		if bundle {
+			if err := os.Rename(download, file.Local); err != nil {
+				return nil, 0, fmt.Errorf("failed to write %s", file.Local)
+			}
+		} else if err := wrapVersionFile(download, file.Local, file.JarPath()); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	info, err := os.Stat(file.Local)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s", file.Local)
+	}
+
+	checksum, err := FileChecksum(file.Local)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return checksum, info.Size(), nil
+}
+
+// packagedVersionFile checks if the local file already provides the version
+// file, either as the bundle itself or as the jar wrapped within a zip.
+func packagedVersionFile(file *BuildFile) bool {
+	if file.Version.File == nil || file.Version.File.MD5 == "" {
+		return false
+	}
+
+	if checksum, err := FileChecksum(file.Local); err == nil && checksum.MD5 == file.Version.File.MD5 {
+		return true
+	}
+
+	archive, err := zip.OpenReader(file.Local)
+
+	if err != nil {
+		return false
+	}
+
+	defer archive.Close()
+
+	if len(archive.File) != 1 || archive.File[0].Name != file.JarPath() {
+		return false
+	}
+
+	content, err := readZipFile(archive.File[0])
+
+	if err != nil {
+		return false
+	}
+
+	return fmt.Sprintf("%x", md5.Sum(content)) == file.Version.File.MD5
+}
+
+// wrapVersionFile writes a zip to the target which contains the source file
+// with the given name.
+func wrapVersionFile(source, target, name string) error {
+	content, err := ioutil.ReadFile(source)
+
+	if err != nil {
+		return fmt.Errorf("failed to read %s", source)
+	}
+
+	tmpfile, err := ioutil.TempFile(filepath.Dir(target), ".package")
+
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file")
+	}
+
+	defer os.Remove(tmpfile.Name())
+
+	archive := zip.NewWriter(tmpfile)
+
+	if err := addArchiveFile(archive, name, content); err != nil {
+		tmpfile.Close()
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		tmpfile.Close()
+		return fmt.Errorf("failed to write %s", target)
+	}
+
+	if err := tmpfile.Close(); err != nil {
+		return fmt.Errorf("failed to write %s", target)
+	}
+
+	if err := os.Chmod(tmpfile.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s", target)
+	}
+
+	if err := os.Rename(tmpfile.Name(), target); err != nil {
+		return fmt.Errorf("failed to write %s", target)
+	}
+
+	return nil
+}
+
+// FetchVersionFile downloads the file of a version to the target path and
+// verifies it against the MD5 hash provided by the server. An existing file
+// with a matching hash is kept as it is.
+func FetchVersionFile(version *kleister.Version, target string) (*Checksum, int64, error) {
+	if version.File == nil || version.File.URL == "" {
+		return nil, 0, fmt.Errorf("version has no file")
+	}
+
+	if info, err := os.Stat(target); err == nil && version.File.MD5 != "" {
+		if checksum, err := FileChecksum(target); err == nil && checksum.MD5 == version.File.MD5 {
+			return checksum, info.Size(), nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, 0, fmt.Errorf("failed to create %s", filepath.Dir(target))
+	}
+
+	resp, err := http.Get(version.File.URL)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download %s", version.File.URL)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to download %s, status %d", version.File.URL, resp.StatusCode)
+	}
+
+	tmpfile, err := ioutil.TempFile(filepath.Dir(target), ".download")
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create a temporary file")
+	}
+
+	defer os.Remove(tmpfile.Name())
+
+	sha := sha256.New()
+	sum := md5.New()
+
+	size, err := io.Copy(io.MultiWriter(tmpfile, sha, sum), resp.Body)
+	tmpfile.Close()
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download %s", version.File.URL)
+	}
+
+	checksum := &Checksum{
+		SHA256: hex.EncodeToString(sha.Sum(nil)),
+		MD5:    hex.EncodeToString(sum.Sum(nil)),
+	}
+
+	if version.File.MD5 != "" && version.File.MD5 != checksum.MD5 {
+		return nil, 0, fmt.Errorf("checksum mismatch, expected md5 %s but got %s", version.File.MD5, checksum.MD5)
+	}
+
+	if err := os.Chmod(tmpfile.Name(), 0644); err != nil {
+		return nil, 0, fmt.Errorf("failed to write %s", target)
+	}
+
+	if err := os.Rename(tmpfile.Name(), target); err != nil {
+		return nil, 0, fmt.Errorf("failed to write %s", target)
+	}
+
+	return checksum, size, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func testZipEntries(t *testing.T, file string) map[string][]byte {
+	archive, err := zip.OpenReader(file)
+
+	if err != nil {
+		t.Fatalf("failed to open %s. %s", file, err)
+	}
+
+	defer archive.Close()
+
+	result := make(map[string][]byte, len(archive.File))
+
+	for _, entry := range archive.File {
+		content, err := readZipFile(entry)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result[entry.Name] = content
+	}
+
+	return result
+}
+
+func TestPackageBuild(t *testing.T) {
+	jar := testZip(t, map[string][]byte{
+		"mcmod.info":         []byte(`[{"modid": "jei"}]`),
+		"mezz/jei/JEI.class": []byte("class"),
+	})
+
+	bundle := testZip(t, map[string][]byte{
+		"mods/baubles-1.5.2.jar": []byte("jar"),
+		"config/baubles.cfg":     []byte("config"),
+	})
+
+	downloads := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+
+		switch r.URL.Path {
+		case "/jei.jar":
+			w.Write(jar)
+		case "/baubles.zip":
+			w.Write(bundle)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	defer server.Close()
+
+	client := newTestClient()
+	client.packs = []*kleister.Pack{{ID: 1, Slug: "demo"}}
+	client.builds = []*kleister.Build{{ID: 2, PackID: 1, Slug: "1.0.0", Name: "Stable"}}
+	client.mods = []*kleister.Mod{{ID: 3, Slug: "jei", Name: "JEI"}, {ID: 4, Slug: "baubles", Name: "Baubles"}}
+	client.versions = []*kleister.Version{
+		{ID: 10, ModID: 3, Slug: "4.16.0", File: &kleister.Attachment{URL: server.URL + "/jei.jar", MD5: fmt.Sprintf("%x", md5.Sum(jar))}},
+		{ID: 11, ModID: 4, Slug: "1.5.2", File: &kleister.Attachment{URL: server.URL + "/baubles.zip", MD5: fmt.Sprintf("%x", md5.Sum(bundle))}},
+	}
+	client.pins[2] = []int64{10, 11}
+
+	out := t.TempDir()
+
+	for run := 0; run < 2; run++ {
+		if _, err := PackageBuild(client, "demo", "1.0.0", out, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if downloads != 2 {
+		t.Errorf("expected packaged files to be reused, got %d downloads", downloads)
+	}
+
+	if leftovers, _ := filepath.Glob(filepath.Join(out, "mods", "*", ".*")); len(leftovers) > 0 {
+		t.Errorf("expected no temporary files, got %v", leftovers)
+	}
+
+	if leftovers, _ := filepath.Glob(filepath.Join(out, "mods", "*", "*.download")); len(leftovers) > 0 {
+		t.Errorf("expected no downloads, got %v", leftovers)
+	}
+
+	wrapped := testZipEntries(t, filepath.Join(out, "mods", "jei", "jei-4.16.0.zip"))
+
+	if want := map[string][]byte{"mods/jei-4.16.0.jar": jar}; !reflect.DeepEqual(wrapped, want) {
+		t.Errorf("expected the jar to be wrapped, got %v", wrapped)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(out, "mods", "baubles", "baubles-1.5.2.zip"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(content, bundle) {
+		t.Errorf("expected the bundle to be kept as it is")
+	}
+
+	content, err = ioutil.ReadFile(filepath.Join(out, "index.json"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index := &PackageIndex{}
+
+	if err := json.Unmarshal(content, index); err != nil {
+		t.Fatal(err)
+	}
+
+	if index.Pack != "demo" || index.Build != "1.0.0" || index.Name != "Stable" || index.Artifact != "" {
+		t.Errorf("unexpected index %+v", index)
+	}
+
+	paths := []string{}
+
+	for _, entry := range index.Mods {
+		paths = append(paths, entry.Path)
+
+		local := filepath.Join(out, filepath.FromSlash(entry.Path))
+		checksum, err := FileChecksum(local)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if entry.MD5 != checksum.MD5 || entry.SHA256 != checksum.SHA256 {
+			t.Errorf("%s: expected checksums of the packaged file, got %s %s", entry.Path, entry.MD5, entry.SHA256)
+		}
+	}
+
+	if want := []string{"mods/baubles/baubles-1.5.2.zip", "mods/jei/jei-4.16.0.zip"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected paths %v, got %v", want, paths)
+	}
+
+	if _, err := PackageBuild(client, "demo", "1.0.0", out, "client"); err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := testZipEntries(t, filepath.Join(out, "demo-1.0.0-client.zip"))
+	names := []string{}
+
+	for name := range artifact {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	if want := []string{"config/baubles.cfg", "mods/baubles-1.5.2.jar", "mods/jei-4.16.0.jar"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected artifact entries %v, got %v", want, names)
+	}
+
+	if !bytes.Equal(artifact["mods/jei-4.16.0.jar"], jar) {
+		t.Errorf("expected the original jar within the artifact")
+	}
+}
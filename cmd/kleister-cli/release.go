@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/guregu/null.v3"
+	"gopkg.in/yaml.v2"
+)
+
+// Release represents a promotion of a build together with the pointers and
+// state it replaced, that's required to roll it back.
+type Release struct {
+	Server              string    `yaml:"server"`
+	Pack                int64     `yaml:"pack"`
+	Build               int64     `yaml:"build"`
+	Slug                string    `yaml:"slug"`
+	Recommended         bool      `yaml:"recommended,omitempty"`
+	Published           bool      `yaml:"published,omitempty"`
+	PreviousLatest      null.Int  `yaml:"previous_latest"`
+	PreviousRecommended null.Int  `yaml:"previous_recommended"`
+	CreatedAt           time.Time `yaml:"created_at"`
+}
+
+// ReleaseHistory represents the local file recording all releases.
+type ReleaseHistory struct {
+	Releases []*Release `yaml:"releases,omitempty"`
+}
+
+// ReleaseHistoryPath returns the path of the release history, it's stored
+// next to the configuration file.
+func ReleaseHistoryPath() string {
+	return filepath.Join(filepath.Dir(ConfigPath()), "releases.yml")
+}
+
+// LoadReleaseHistory reads the release history, a missing file results in an
+// empty history.
+func LoadReleaseHistory() (*ReleaseHistory, error) {
+	result := &ReleaseHistory{}
+	content, err := ioutil.ReadFile(ReleaseHistoryPath())
+
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release history")
+	}
+
+	if err := yaml.Unmarshal(content, result); err != nil {
+		return nil, fmt.Errorf("failed to parse release history. %s", err)
+	}
+
+	return result, nil
+}
+
+// Save writes the release history.
+func (h *ReleaseHistory) Save() error {
+	path := ReleaseHistoryPath()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config dir")
+	}
+
+	content, err := yaml.Marshal(h)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode release history. %s", err)
+	}
+
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write release history")
+	}
+
+	return nil
+}
+
+// Last returns the index of the most recent release of the pack on the
+// server or -1 if there is none.
+func (h *ReleaseHistory) Last(server string, pack int64) int {
+	for i := len(h.Releases) - 1; i >= 0; i-- {
+		if h.Releases[i].Server == server && h.Releases[i].Pack == pack {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// PreflightRelease checks if a build can be promoted, private and empty builds
+// are always refused while hidden builds are only accepted to get published.
+func PreflightRelease(client kleister.ClientAPI, pack *kleister.Pack, build *kleister.Build, publish bool) error {
+	problems := make([]string, 0)
+
+	if build.Private {
+		problems = append(problems, "it's private")
+	}
+
+	if !build.Published && !publish {
+		problems = append(problems, "it's hidden")
+	}
+
+	versions, err := client.BuildVersionList(
+		kleister.BuildVersionParams{
+			Pack:  pack.Slug,
+			Build: build.Slug,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		problems = append(problems, "it doesn't contain any versions")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("refusing to release build %s, %s", build.Slug, strings.Join(problems, ", "))
+
+	if !build.Published && !publish {
+		message = message + ", use --publish to publish hidden builds"
+	}
+
+	return errors.New(message)
+}
+
+// ReleaseBuild publishes the build and moves the latest and optionally the
+// recommended pointer of the pack to it. The replaced pointers get recorded
+// within the release history, if the pointers can't be moved the build gets
+// hidden again.
+func ReleaseBuild(client kleister.ClientAPI, server, id, slug string, recommended, publish bool) (*Release, error) {
+	history, err := LoadReleaseHistory()
+
+	if err != nil {
+		return nil, err
+	}
+
+	pack, err := client.PackGet(
+		id,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	build, err := client.BuildGet(
+		pack.Slug,
+		slug,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := PreflightRelease(client, pack, build, publish); err != nil {
+		return nil, err
+	}
+
+	result := &Release{
+		Server:              server,
+		Pack:                pack.ID,
+		Build:               build.ID,
+		Slug:                build.Slug,
+		Recommended:         recommended,
+		Published:           !build.Published,
+		PreviousLatest:      pack.LatestID,
+		PreviousRecommended: pack.RecommendedID,
+		CreatedAt:           time.Now().UTC(),
+	}
+
+	if !build.Published {
+		build.Published = true
+
+		if _, err := client.BuildPatch(pack.Slug, build); err != nil {
+			return nil, err
+		}
+	}
+
+	pack.LatestID = null.IntFrom(build.ID)
+
+	if recommended {
+		pack.RecommendedID = null.IntFrom(build.ID)
+	}
+
+	if _, err := client.PackPatch(pack); err != nil {
+		if !result.Published {
+			return nil, err
+		}
+
+		build.Published = false
+
+		if _, revert := client.BuildPatch(pack.Slug, build); revert != nil {
+			return nil, fmt.Errorf("%s, failed to hide build %s again", err, build.Slug)
+		}
+
+		return nil, err
+	}
+
+	history.Releases = append(history.Releases, result)
+
+	if err := history.Save(); err != nil {
+		return nil, fmt.Errorf("released build %s but failed to record it, a rollback is not possible. %s", build.Slug, err)
+	}
+
+	return result, nil
+}
+
+// RollbackRelease restores the pointers replaced by the most recent release of
+// the pack and hides the build again if the release published it.
+func RollbackRelease(client kleister.ClientAPI, server, id string) (*Release, error) {
+	history, err := LoadReleaseHistory()
+
+	if err != nil {
+		return nil, err
+	}
+
+	pack, err := client.PackGet(
+		id,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	index := history.Last(server, pack.ID)
+
+	if index < 0 {
+		return nil, fmt.Errorf("no release of pack %s recorded", pack.Slug)
+	}
+
+	result := history.Releases[index]
+
+	if pack.LatestID.Int64 != result.Build || (result.Recommended && pack.RecommendedID.Int64 != result.Build) {
+		return nil, fmt.Errorf("pointers of pack %s changed since the release of build %s", pack.Slug, result.Slug)
+	}
+
+	pack.LatestID = result.PreviousLatest
+
+	if result.Recommended {
+		pack.RecommendedID = result.PreviousRecommended
+	}
+
+	if _, err := client.PackPatch(pack); err != nil {
+		return nil, err
+	}
+
+	if result.Published {
+		build, err := client.BuildGet(
+			pack.Slug,
+			fmt.Sprintf("%d", result.Build),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		build.Published = false
+
+		if _, err := client.BuildPatch(pack.Slug, build); err != nil {
+			return nil, err
+		}
+	}
+
+	history.Releases = append(history.Releases[:index], history.Releases[index+1:]...)
+
+	if err := history.Save(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/guregu/null.v3"
+)
+
+// testReleaseServer serves a pack with a hidden build, patching the pack
+// fails if requested.
+type testReleaseServer struct {
+	fail  bool
+	pack  *kleister.Pack
+	build *kleister.Build
+}
+
+func (s *testReleaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/packs/demo":
+		json.NewEncoder(w).Encode(s.pack)
+	case r.Method == "PUT" && r.URL.Path == "/api/packs/1":
+		if s.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"message": "failed to update pack"})
+			return
+		}
+
+		json.NewDecoder(r.Body).Decode(s.pack)
+		json.NewEncoder(w).Encode(s.pack)
+	case r.Method == "GET" && r.URL.Path == "/api/packs/demo/builds/1.0.0":
+		json.NewEncoder(w).Encode(s.build)
+	case r.Method == "PUT" && r.URL.Path == "/api/packs/demo/builds/2":
+		json.NewDecoder(r.Body).Decode(s.build)
+		json.NewEncoder(w).Encode(s.build)
+	case r.Method == "GET" && r.URL.Path == "/api/packs/demo/builds/1.0.0/versions":
+		json.NewEncoder(w).Encode([]*kleister.BuildVersion{{Version: &kleister.Version{ID: 3}}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestReleaseBuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      bool
+		latest    null.Int
+		published bool
+		releases  int
+	}{
+		{"released", false, null.IntFrom(2), true, 1},
+		{"pack patch failed", true, null.IntFrom(1), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+			handler := &testReleaseServer{
+				fail: tt.fail,
+				pack: &kleister.Pack{
+					ID:       1,
+					Slug:     "demo",
+					LatestID: null.IntFrom(1),
+				},
+				build: &kleister.Build{
+					ID:   2,
+					Slug: "1.0.0",
+				},
+			}
+
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			_, err := ReleaseBuild(kleister.NewClient(server.URL), server.URL, "demo", "1.0.0", false, true)
+
+			if tt.fail != (err != nil) {
+				t.Fatalf("expected failure %v, got %v", tt.fail, err)
+			}
+
+			if handler.pack.LatestID != tt.latest {
+				t.Errorf("expected latest %v, got %v", tt.latest, handler.pack.LatestID)
+			}
+
+			if handler.build.Published != tt.published {
+				t.Errorf("expected published %v, got %v", tt.published, handler.build.Published)
+			}
+
+			history, err := LoadReleaseHistory()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(history.Releases) != tt.releases {
+				t.Errorf("expected %d releases, got %d", tt.releases, len(history.Releases))
+			}
+
+			if _, err := os.Stat(ReleaseHistoryPath()); tt.releases == 0 && !os.IsNotExist(err) {
+				t.Errorf("expected no release history")
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+var (
+	// scriptVersion matches versions that are safe to use unquoted within the
+	// generated scripts.
+	scriptVersion = regexp.MustCompile(`^[0-9A-Za-z._+-]+$`)
+)
+
+// tmplServerStartShell represents the start script of a server on Unix.
+var tmplServerStartShell = `#!/bin/sh
+# Start script for {{ .Pack }} {{ .Build }}, generated by kleister-cli.
+cd "$(dirname "$0")" || exit 1
+
+JAVA="${JAVA:-java}"
+JVM_ARGS="{{ .JVMArgs }}"
+{{- if .Java }}
+
+VERSION=$("$JAVA" -version 2>&1 | awk -F '"' '/version/ { print $2; exit }')
+MAJOR="${VERSION%%.*}"
+
+if [ "$MAJOR" = "1" ]; then
+	MAJOR=$(echo "$VERSION" | cut -d. -f2)
+fi
+
+if [ -z "$MAJOR" ] || [ "$MAJOR" -lt {{ .Java }} ]; then
+	echo "Java {{ .Java }} or newer is required, found ${VERSION:-none}" >&2
+	exit 1
+fi
+{{- end }}
+{{- if .Args }}
+
+if [ -f "{{ .Args }}/unix_args.txt" ]; then
+	exec "$JAVA" $JVM_ARGS "@{{ .Args }}/unix_args.txt" nogui "$@"
+fi
+{{- end }}
+
+JAR=$(ls {{ .Jar }} 2>/dev/null | grep -v installer | head -n 1)
+
+if [ -z "$JAR" ]; then
+	echo "Failed to find the server jar, run the installer first" >&2
+	exit 1
+fi
+
+exec "$JAVA" $JVM_ARGS -jar "$JAR" nogui "$@"
+`
+
+// tmplServerStartBatch represents the start script of a server on Windows.
+var tmplServerStartBatch = `@echo off
+rem Start script for {{ .Pack }} {{ .Build }}, generated by kleister-cli.
+{{- if .Java }}
+rem Requires Java {{ .Java }} or newer.
+{{- end }}
+cd /d "%~dp0"
+
+if "%JAVA%"=="" set JAVA=java
+set JVM_ARGS={{ .JVMArgs }}
+{{- if .Args }}
+
+if exist "{{ .Args }}/win_args.txt" (
+	"%JAVA%" %JVM_ARGS% "@{{ .Args }}/win_args.txt" nogui %*
+	goto :eof
+)
+{{- end }}
+
+set JAR=
+for %%f in ({{ .Jar }}) do (
+	echo %%f | findstr /i /v installer >nul && set JAR=%%f
+)
+
+if "%JAR%"=="" (
+	echo Failed to find the server jar, run the installer first
+	exit /b 1
+)
+
+"%JAVA%" %JVM_ARGS% -jar "%JAR%" nogui %*
+`
+
+// ServerLaunch represents the settings required to start a server.
+type ServerLaunch struct {
+	Pack    string
+	Build   string
+	Java    int64
+	Memory  int64
+	JVMArgs string
+	Jar     string
+	Args    string
+}
+
+// NewServerLaunch derives the launch settings of a server from the build, the
+// memory is taken from MinMemory and the required Java version from MinJava.
+func NewServerLaunch(pack *kleister.Pack, build *kleister.Build, minecraft *kleister.Minecraft, forge *kleister.Forge) (*ServerLaunch, error) {
+	result := &ServerLaunch{
+		Pack:  SingleLine(pack.Name),
+		Build: SingleLine(build.Name),
+		Jar:   "minecraft_server.jar",
+	}
+
+	if minecraft != nil && !scriptVersion.MatchString(minecraft.Version) {
+		return nil, fmt.Errorf("invalid minecraft version %q", minecraft.Version)
+	}
+
+	if forge != nil && !scriptVersion.MatchString(ForgeVersion(forge)) {
+		return nil, fmt.Errorf("invalid forge version %q", ForgeVersion(forge))
+	}
+
+	if build.MinMemory != "" {
+		memory, err := MemoryMegabytes(build.MinMemory)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.Memory = memory
+		result.JVMArgs = fmt.Sprintf("-Xms%dM -Xmx%dM", memory, memory)
+	}
+
+	if build.MinJava != "" {
+		matches := javaPattern.FindStringSubmatch(build.MinJava)
+
+		if matches == nil {
+			return nil, fmt.Errorf("invalid java version %s", build.MinJava)
+		}
+
+		result.Java, _ = strconv.ParseInt(matches[1], 10, 64)
+	}
+
+	if minecraft != nil {
+		result.Jar = fmt.Sprintf("minecraft_server.%s.jar", minecraft.Version)
+	}
+
+	if forge != nil {
+		version := ForgeVersion(forge)
+
+		if minecraft != nil {
+			version = fmt.Sprintf("%s-%s", minecraft.Version, version)
+		}
+
+		result.Jar = fmt.Sprintf("forge-%s*.jar", version)
+
+		if minecraft != nil && CompareVersions(minecraft.Version, "1.17") >= 0 {
+			result.Args = fmt.Sprintf("libraries/net/minecraftforge/forge/%s", version)
+		}
+	}
+
+	return result, nil
+}
+
+// Scripts renders the start scripts for Unix and Windows.
+func (l *ServerLaunch) Scripts() (map[string]string, error) {
+	result := make(map[string]string, 2)
+
+	for name, format := range map[string]string{
+		"start.sh":  tmplServerStartShell,
+		"start.bat": tmplServerStartBatch,
+	} {
+		content, err := renderServerTemplate(format, l)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(name, ".bat") {
+			content = strings.Replace(content, "\n", "\r\n", -1)
+		}
+
+		result[name] = content
+	}
+
+	return result, nil
+}
+
+// SingleLine replaces line breaks and other control characters by spaces, it
+// prevents names from breaking out of comments or values within generated
+// scripts and configs.
+func SingleLine(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+
+		return r
+	}, value)
+}
+
+// SupportsSide checks if the mod is used on the side, an empty side or the
+// side both matches all mods.
+func SupportsSide(mod *kleister.Mod, side string) bool {
+	switch {
+	case side == "" || side == "both":
+		return true
+	case mod.Side == "" || mod.Side == "both":
+		return true
+	}
+
+	return mod.Side == side
+}
+
+// renderServerTemplate executes a server template with the data.
+func renderServerTemplate(format string, data interface{}) (string, error) {
+	tmpl, err := template.New(
+		"_",
+	).Parse(
+		format,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
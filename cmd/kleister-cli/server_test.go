@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestSingleLine(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Demo", "Demo"},
+		{"Demo\nrm -rf ~", "Demo rm -rf ~"},
+		{"Demo\r\n& del *", "Demo  & del *"},
+		{"Tab\there\x00", "Tab here "},
+		{"Ünïcode", "Ünïcode"},
+	}
+
+	for _, tt := range tests {
+		if got := SingleLine(tt.value); got != tt.want {
+			t.Errorf("SingleLine(%q): expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestServerLaunchScripts(t *testing.T) {
+	launch, err := NewServerLaunch(
+		&kleister.Pack{Name: "Demo\necho injected"},
+		&kleister.Build{Name: "1.0.0\r\necho injected", MinJava: "1.8", MinMemory: "2G"},
+		&kleister.Minecraft{Version: "1.12.2"},
+		&kleister.Forge{Version: "14.23.5.2847", Minecraft: "1.12.2"},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scripts, err := launch.Scripts()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range scripts {
+		for _, line := range strings.Split(content, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "echo injected") {
+				t.Errorf("%s: name broke out of the comment", name)
+			}
+		}
+
+		if !strings.Contains(content, "-Xms2048M -Xmx2048M") {
+			t.Errorf("%s: expected the memory settings", name)
+		}
+
+		if !strings.Contains(content, "forge-1.12.2-14.23.5.2847*.jar") {
+			t.Errorf("%s: expected the forge jar", name)
+		}
+	}
+}
+
+func TestServerLaunchVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		minecraft *kleister.Minecraft
+		forge     *kleister.Forge
+	}{
+		{"minecraft", &kleister.Minecraft{Version: "1.12.2; rm -rf ~"}, nil},
+		{"forge", &kleister.Minecraft{Version: "1.12.2"}, &kleister.Forge{Version: "$(id)"}},
+	}
+
+	for _, tt := range tests {
+		_, err := NewServerLaunch(&kleister.Pack{Name: "Demo"}, &kleister.Build{Name: "1.0.0"}, tt.minecraft, tt.forge)
+
+		if err == nil {
+			t.Errorf("%s: expected an error for an unsafe version", tt.name)
+		}
+	}
+}
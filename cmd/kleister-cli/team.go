@@ -1,11 +1,8 @@
 package main
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"os"
-	"text/template"
 
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
@@ -66,15 +63,30 @@ func Team() *cli.Command {
 						Value: tmplTeamList,
 						Usage: "Custom output format",
 					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "Comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "Sort by a field, prefix with a dash for descending order",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Value: "",
+						Usage: "Filter by expression on the record fields",
+					},
 					&cli.BoolFlag{
-						Name:  "json",
+						Name:  "first",
 						Value: false,
-						Usage: "Print in JSON format",
+						Usage: "Return only first record",
 					},
 					&cli.BoolFlag{
-						Name:  "xml",
+						Name:  "last",
 						Value: false,
-						Usage: "Print in XML format",
+						Usage: "Return only last record",
 					},
 				},
 				Action: func(c *cli.Context) error {
@@ -96,16 +108,6 @@ func Team() *cli.Command {
 						Value: tmplTeamShow,
 						Usage: "Custom output format",
 					},
-					&cli.BoolFlag{
-						Name:  "json",
-						Value: false,
-						Usage: "Print in JSON format",
-					},
-					&cli.BoolFlag{
-						Name:  "xml",
-						Value: false,
-						Usage: "Print in XML format",
-					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, TeamShow)
@@ -192,15 +194,30 @@ func Team() *cli.Command {
 								Value: tmplTeamUserList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -300,15 +317,30 @@ func Team() *cli.Command {
 								Value: tmplTeamPackList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -408,15 +440,30 @@ func Team() *cli.Command {
 								Value: tmplTeamModList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -508,60 +555,7 @@ func TeamList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // TeamShow provides the sub-command to show team details.
@@ -574,47 +568,7 @@ func TeamShow(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	return tmpl.Execute(os.Stdout, record)
+	return OutputRecord(c, record)
 }
 
 // TeamDelete provides the sub-command to delete a team.
@@ -708,60 +662,7 @@ func TeamUserList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // TeamUserAppend provides the sub-command to append a user to the team.
@@ -829,60 +730,7 @@ func TeamPackList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // TeamPackAppend provides the sub-command to append a pack to the team.
@@ -950,60 +798,7 @@ func TeamModList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // TeamModAppend provides the sub-command to append a mod to the team.
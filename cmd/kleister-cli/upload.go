@@ -0,0 +1,342 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kleister/kleister-go/kleister"
+	"gopkg.in/urfave/cli.v2"
+)
+
+const (
+	// uploadChunkSize defines the size of a single upload request.
+	uploadChunkSize = 8 << 20
+
+	// uploadRetries defines how often a failed chunk gets retried.
+	uploadRetries = 5
+
+	// uploadProtocol defines the version of the tus protocol.
+	uploadProtocol = "1.0.0"
+)
+
+var (
+	// errUploadUnsupported signals that the server doesn't provide the
+	// streaming upload endpoint.
+	errUploadUnsupported = errors.New("streaming upload is not supported by the server")
+)
+
+// Uploader streams version files in chunks to the server. The protocol is
+// modeled after tus: servers advertise it with the Tus-Resumable and
+// Tus-Extension headers on OPTIONS of the versions of a mod, a POST to the
+// upload endpoint of a version announces the size and returns the location of
+// the upload, HEAD on that location returns the received offset and PATCH
+// appends a chunk at the given offset. Failed chunks are retried from the
+// offset the server reports.
+type Uploader struct {
+	server  string
+	client  *http.Client
+	chunk   int64
+	retries int
+	backoff time.Duration
+}
+
+// NewUploader initializes a new uploader for the server.
+func NewUploader(server, token string) *Uploader {
+	return &Uploader{
+		server: strings.TrimRight(server, "/"),
+		client: &http.Client{
+			Transport: &authTransport{
+				token: token,
+				base:  defaultTransport(),
+			},
+		},
+		chunk:   uploadChunkSize,
+		retries: uploadRetries,
+		backoff: time.Second,
+	}
+}
+
+// Supported checks if the server advertises streaming uploads for the
+// versions of the mod.
+func (u *Uploader) Supported(mod string) bool {
+	uri := fmt.Sprintf(
+		"%s/api/mods/%s/versions",
+		u.server,
+		url.PathEscape(mod),
+	)
+
+	req, err := http.NewRequest("OPTIONS", uri, nil)
+
+	if err != nil {
+		return false
+	}
+
+	resp, err := u.do(req)
+
+	if err != nil {
+		return false
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return false
+	}
+
+	if resp.Header.Get("Tus-Resumable") == "" {
+		return false
+	}
+
+	for _, extension := range strings.Split(resp.Header.Get("Tus-Extension"), ",") {
+		if strings.TrimSpace(extension) == "creation" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Upload streams the file to the version of the mod, it returns
+// errUploadUnsupported if the server doesn't support streaming uploads.
+func (u *Uploader) Upload(mod, version, path string) error {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return fmt.Errorf("failed to open file")
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+
+	if err != nil {
+		return fmt.Errorf("failed to stat file")
+	}
+
+	location, err := u.create(mod, version, filepath.Base(path), info.Size())
+
+	if err != nil {
+		return err
+	}
+
+	offset := int64(0)
+	failures := 0
+
+	for offset < info.Size() {
+		u.progress(filepath.Base(path), offset, info.Size())
+		next, err := u.patch(location, file, offset, info.Size())
+
+		if err == nil && next <= offset {
+			err = fmt.Errorf("server didn't advance the offset beyond %d", offset)
+		}
+
+		if err == nil {
+			offset = next
+			failures = 0
+
+			continue
+		}
+
+		failures++
+
+		if failures > u.retries {
+			fmt.Fprintf(os.Stderr, "\n")
+			return fmt.Errorf("failed to upload file. %s", err)
+		}
+
+		time.Sleep(u.backoff * time.Duration(1<<uint(failures-1)))
+
+		if current, err := u.offset(location); err == nil {
+			offset = current
+		}
+	}
+
+	u.progress(filepath.Base(path), info.Size(), info.Size())
+	fmt.Fprintf(os.Stderr, "\n")
+
+	return nil
+}
+
+// create announces a new upload and returns its location.
+func (u *Uploader) create(mod, version, name string, size int64) (string, error) {
+	uri := fmt.Sprintf(
+		"%s/api/mods/%s/versions/%s/upload",
+		u.server,
+		url.PathEscape(mod),
+		url.PathEscape(version),
+	)
+
+	req, err := http.NewRequest("POST", uri, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("Upload-Name", name)
+
+	resp, err := u.do(req)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload. %s", err)
+	}
+
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return "", errUploadUnsupported
+	default:
+		return "", fmt.Errorf("failed to create upload, server responded with %s", resp.Status)
+	}
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+
+	if err != nil || resp.Header.Get("Location") == "" {
+		return "", fmt.Errorf("failed to create upload, missing location")
+	}
+
+	return location.String(), nil
+}
+
+// offset requests the number of bytes already received by the server.
+func (u *Uploader) offset(location string) (int64, error) {
+	req, err := http.NewRequest("HEAD", location, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := u.do(req)
+
+	if err != nil {
+		return 0, err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return 0, fmt.Errorf("server responded with %s", resp.Status)
+	}
+
+	return strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+}
+
+// patch sends the chunk starting at the offset and returns the new offset.
+func (u *Uploader) patch(location string, file *os.File, offset, size int64) (int64, error) {
+	length := u.chunk
+
+	if offset+length > size {
+		length = size - offset
+	}
+
+	req, err := http.NewRequest(
+		"PATCH",
+		location,
+		io.NewSectionReader(file, offset, length),
+	)
+
+	if err != nil {
+		return offset, err
+	}
+
+	req.ContentLength = length
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+
+	resp, err := u.do(req)
+
+	if err != nil {
+		return offset, err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return offset, fmt.Errorf("server responded with %s", resp.Status)
+	}
+
+	next, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+
+	if err != nil {
+		return offset + length, nil
+	}
+
+	return next, nil
+}
+
+// do sends the request including the protocol version.
+func (u *Uploader) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Tus-Resumable", uploadProtocol)
+	return u.client.Do(req)
+}
+
+// progress prints the progress of the upload to stderr.
+func (u *Uploader) progress(name string, done, total int64) {
+	percent := int64(100)
+
+	if total > 0 {
+		percent = done * 100 / total
+	}
+
+	fmt.Fprintf(
+		os.Stderr,
+		"\ruploading %s %.1f/%.1f MiB (%d%%)",
+		name,
+		float64(done)/(1<<20),
+		float64(total)/(1<<20),
+		percent,
+	)
+}
+
+// prepareUpload returns an uploader if the server advertises streaming
+// uploads for the mod, otherwise the file gets encoded into the record to be
+// sent together with the version itself.
+func prepareUpload(c *cli.Context, record *kleister.Version, mod, path string) (*Uploader, error) {
+	server, token, _ := ResolveCredentials(c)
+	uploader := NewUploader(server, token)
+
+	if uploader.Supported(mod) {
+		return uploader, nil
+	}
+
+	if err := record.EncodeFile(path); err != nil {
+		return nil, fmt.Errorf("failed to encode file")
+	}
+
+	return nil, nil
+}
+
+// uploadVersionFile streams the file to the version, it falls back to an
+// encoded upload if the upload endpoint is missing anyway.
+func uploadVersionFile(client kleister.ClientAPI, uploader *Uploader, mod string, record *kleister.Version, path string) error {
+	err := uploader.Upload(
+		mod,
+		record.Slug,
+		path,
+	)
+
+	if err != errUploadUnsupported {
+		return err
+	}
+
+	if err := record.EncodeFile(path); err != nil {
+		return fmt.Errorf("failed to encode file")
+	}
+
+	_, err = client.VersionPatch(
+		mod,
+		record,
+	)
+
+	return err
+}
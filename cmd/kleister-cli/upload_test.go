@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+// testUploadServer implements the upload protocol for a single version, it
+// stores half of a chunk before failing to simulate interrupted transfers.
+type testUploadServer struct {
+	sync.Mutex
+
+	extension string
+	creation  bool
+	failures  int
+	stalled   bool
+	data      []byte
+	patches   int
+	encoded   string
+}
+
+func (s *testUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch {
+	case r.Method == "OPTIONS" && r.URL.Path == "/api/mods/jei/versions":
+		if s.extension != "" {
+			w.Header().Set("Tus-Resumable", uploadProtocol)
+			w.Header().Set("Tus-Extension", s.extension)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "POST" && r.URL.Path == "/api/mods/jei/versions/1.0.0/upload":
+		if !s.creation {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Location", "/uploads/1")
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "HEAD" && r.URL.Path == "/uploads/1":
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == "PATCH" && r.URL.Path == "/uploads/1":
+		s.patches++
+
+		if r.Header.Get("Upload-Offset") != strconv.Itoa(len(s.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if s.failures > 0 {
+			s.failures--
+			s.data = append(s.data, body[:len(body)/2]...)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !s.stalled {
+			s.data = append(s.data, body...)
+		}
+
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PUT" && r.URL.Path == "/api/mods/jei/versions/1":
+		record := &kleister.Version{}
+
+		if err := json.NewDecoder(r.Body).Decode(record); err != nil || record.File == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.encoded = record.File.Upload
+		json.NewEncoder(w).Encode(record)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func testUploader(url string) *Uploader {
+	return &Uploader{
+		server:  url,
+		client:  http.DefaultClient,
+		chunk:   4,
+		retries: 3,
+		backoff: 0,
+	}
+}
+
+func testUploadFile(t *testing.T, content []byte) string {
+	path := filepath.Join(t.TempDir(), "mod.jar")
+
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestUploaderSupported(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		want      bool
+	}{
+		{"advertised", "creation, termination", true},
+		{"without creation", "termination", false},
+		{"not advertised", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(&testUploadServer{extension: tt.extension})
+			defer server.Close()
+
+			if got := testUploader(server.URL).Supported("jei"); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUploaderUpload(t *testing.T) {
+	content := []byte("kleister streams this file in chunks")
+
+	tests := []struct {
+		name     string
+		failures int
+		stalled  bool
+		patches  int
+		fail     bool
+	}{
+		{"complete", 0, false, 9, false},
+		{"resume", 2, false, 10, false},
+		{"retries exceeded", 4, false, 4, true},
+		{"offset stalled", 0, true, 4, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &testUploadServer{creation: true, failures: tt.failures, stalled: tt.stalled}
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			err := testUploader(server.URL).Upload("jei", "1.0.0", testUploadFile(t, content))
+
+			if tt.fail {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+
+				if handler.patches != tt.patches {
+					t.Errorf("expected %d patches, got %d", tt.patches, handler.patches)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(handler.data, content) {
+				t.Errorf("expected %q, got %q", content, handler.data)
+			}
+
+			if handler.patches != tt.patches {
+				t.Errorf("expected %d patches, got %d", tt.patches, handler.patches)
+			}
+		})
+	}
+}
+
+func TestUploadVersionFileFallback(t *testing.T) {
+	handler := &testUploadServer{extension: "creation"}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	record := &kleister.Version{
+		ID:   1,
+		Slug: "1.0.0",
+	}
+
+	err := uploadVersionFile(
+		kleister.NewClient(server.URL),
+		testUploader(server.URL),
+		"jei",
+		record,
+		testUploadFile(t, []byte("kleister")),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if handler.encoded == "" {
+		t.Errorf("expected an encoded upload")
+	}
+
+	if len(handler.data) != 0 {
+		t.Errorf("expected no streamed data, got %q", handler.data)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+var (
+	// javaPattern matches Java versions like 1.8, 8 or 17.0.2.
+	javaPattern = regexp.MustCompile(`^(?:1\.)?([0-9]+)`)
+)
+
+// javaRequirements defines the minimal Java version required by Minecraft
+// versions, ordered from the newest to the oldest Minecraft version.
+var javaRequirements = []struct {
+	minecraft string
+	java      int64
+}{
+	{"1.20.5", 21},
+	{"1.18", 17},
+	{"1.17", 16},
+	{"0", 8},
+}
+
+// BuildIssue represents a configuration issue of a build.
+type BuildIssue struct {
+	Build   string `json:"build" xml:"build"`
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+// ValidateLoader checks that the Forge version is made for the Minecraft
+// version of a build.
+func ValidateLoader(minecraft *kleister.Minecraft, forge *kleister.Forge) error {
+	if forge == nil || forge.Minecraft == "" {
+		return nil
+	}
+
+	if minecraft == nil {
+		return fmt.Errorf("forge %s requires minecraft %s but no minecraft is set", forge.Version, forge.Minecraft)
+	}
+
+	if forge.Minecraft != minecraft.Version {
+		return fmt.Errorf("forge %s requires minecraft %s but minecraft %s is set", forge.Version, forge.Minecraft, minecraft.Version)
+	}
+
+	return nil
+}
+
+// ValidateJava checks that the minimal Java version is valid and fulfills
+// the requirements of the Minecraft and Forge versions.
+func ValidateJava(java string, minecraft *kleister.Minecraft, forge *kleister.Forge) error {
+	if java == "" {
+		return nil
+	}
+
+	matches := javaPattern.FindStringSubmatch(java)
+
+	if matches == nil {
+		return fmt.Errorf("invalid java version %s", java)
+	}
+
+	major, err := strconv.ParseInt(matches[1], 10, 64)
+
+	if err != nil || major < 5 {
+		return fmt.Errorf("invalid java version %s", java)
+	}
+
+	if minecraft == nil {
+		return nil
+	}
+
+	for _, requirement := range javaRequirements {
+		if CompareVersions(minecraft.Version, requirement.minecraft) < 0 {
+			continue
+		}
+
+		if major < requirement.java {
+			return fmt.Errorf("minecraft %s requires java %d but java %s is set", minecraft.Version, requirement.java, java)
+		}
+
+		break
+	}
+
+	if forge != nil && CompareVersions(minecraft.Version, "1.13") < 0 && major > 8 {
+		return fmt.Errorf("forge for minecraft %s only runs on java 8 but java %s is set", minecraft.Version, java)
+	}
+
+	return nil
+}
+
+// ValidateBuild checks the loader and Java settings of a build.
+func ValidateBuild(client kleister.ClientAPI, build *kleister.Build) ([]*BuildIssue, error) {
+	minecraft, forge, err := ResolveLoader(
+		client,
+		build,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*BuildIssue, 0)
+
+	if err := ValidateLoader(minecraft, forge); err != nil {
+		result = append(result, &BuildIssue{
+			Build:   build.Slug,
+			Field:   "forge",
+			Message: err.Error(),
+		})
+	}
+
+	if err := ValidateJava(build.MinJava, minecraft, forge); err != nil {
+		result = append(result, &BuildIssue{
+			Build:   build.Slug,
+			Field:   "java",
+			Message: err.Error(),
+		})
+	}
+
+	return result, nil
+}
+
+// checkBuildLoader validates the Minecraft and Forge versions referenced by a
+// build before it gets saved, mismatches are refused unless forced.
+func checkBuildLoader(client kleister.ClientAPI, record *kleister.Build, force bool) error {
+	minecraft, forge, err := ResolveLoader(
+		client,
+		&kleister.Build{
+			MinecraftID: record.MinecraftID,
+			ForgeID:     record.ForgeID,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateLoader(minecraft, forge); err != nil {
+		if !force {
+			return fmt.Errorf("%s, use --force to save anyway", err)
+		}
+
+		fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kleister/kleister-go/kleister"
+)
+
+func TestValidateJava(t *testing.T) {
+	tests := []struct {
+		java      string
+		minecraft string
+		forge     bool
+		fail      bool
+	}{
+		{"", "1.18.2", false, false},
+		{"1.8", "", false, false},
+		{"invalid", "", false, true},
+		{"1.4", "", false, true},
+		{"1.8", "1.12.2", true, false},
+		{"8", "1.12.2", false, false},
+		{"11", "1.12.2", true, true},
+		{"11", "1.12.2", false, false},
+		{"11", "1.16.5", true, false},
+		{"8", "1.17.1", false, true},
+		{"16", "1.17.1", false, false},
+		{"16", "1.18.2", true, true},
+		{"17.0.2", "1.18.2", true, false},
+		{"17", "1.20.6", false, true},
+		{"21", "1.20.6", false, false},
+	}
+
+	for _, tt := range tests {
+		var (
+			minecraft *kleister.Minecraft
+			forge     *kleister.Forge
+		)
+
+		if tt.minecraft != "" {
+			minecraft = &kleister.Minecraft{
+				Version: tt.minecraft,
+			}
+		}
+
+		if tt.forge {
+			forge = &kleister.Forge{
+				Version:   "forge",
+				Minecraft: tt.minecraft,
+			}
+		}
+
+		err := ValidateJava(tt.java, minecraft, forge)
+
+		if tt.fail && err == nil {
+			t.Errorf("ValidateJava(%q, %q, %v): expected an error", tt.java, tt.minecraft, tt.forge)
+		}
+
+		if !tt.fail && err != nil {
+			t.Errorf("ValidateJava(%q, %q, %v): unexpected error %s", tt.java, tt.minecraft, tt.forge, err)
+		}
+	}
+}
+
+func TestValidateLoader(t *testing.T) {
+	minecraft := &kleister.Minecraft{
+		Version: "1.12.2",
+	}
+
+	tests := []struct {
+		name      string
+		minecraft *kleister.Minecraft
+		forge     *kleister.Forge
+		fail      bool
+	}{
+		{"without forge", minecraft, nil, false},
+		{"matching", minecraft, &kleister.Forge{Version: "14.23.5.2847", Minecraft: "1.12.2"}, false},
+		{"mismatch", minecraft, &kleister.Forge{Version: "25.0.1", Minecraft: "1.13.2"}, true},
+		{"without minecraft", nil, &kleister.Forge{Version: "25.0.1", Minecraft: "1.13.2"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateLoader(tt.minecraft, tt.forge); (err != nil) != tt.fail {
+			t.Errorf("%s: expected failure %v, got %v", tt.name, tt.fail, err)
+		}
+	}
+}
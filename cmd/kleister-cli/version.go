@@ -1,13 +1,12 @@
 package main
 
 import (
-	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"os"
 	"regexp"
 	"strconv"
-	"text/template"
+	"strings"
 
 	"github.com/kleister/kleister-go/kleister"
 	"gopkg.in/urfave/cli.v2"
@@ -24,12 +23,22 @@ var tmplVersionShow = "Slug: \x1b[33m{{ .Slug }}\x1b[0m" + `
 ID: {{ .ID }}
 Name: {{ .Name }}{{with .Mod}}
 Mod: {{ . }}{{end}}{{with .File}}
-File: {{ . }}{{end}}{{with .Builds}}
+File: {{ . }}{{with .MD5}}
+MD5: {{ . }}{{end}}{{end}}{{with .SHA256}}
+SHA256: {{ . }}{{end}}{{with .Builds}}
 Builds: {{ buildlist . }}{{end}}
 Created: {{ .CreatedAt.Format "Mon Jan _2 15:04:05 MST 2006" }}
 Updated: {{ .UpdatedAt.Format "Mon Jan _2 15:04:05 MST 2006" }}
 `
 
+// VersionDetails represents a version within details view, the SHA256 hash
+// isn't stored by the server and only gets calculated on request.
+type VersionDetails struct {
+	XMLName xml.Name `json:"-" xml:"Version"`
+	*kleister.Version
+	SHA256 string `json:"sha256,omitempty" xml:",omitempty"`
+}
+
 // tmplVersionBuildList represents a row within version build listing.
 var tmplVersionBuildList = "Slug: \x1b[33m{{ .Build.Slug }}\x1b[0m" + `
 ID: {{ .Build.ID }}
@@ -59,15 +68,30 @@ func Version() *cli.Command {
 						Value: tmplVersionList,
 						Usage: "Custom output format",
 					},
+					&cli.StringFlag{
+						Name:  "columns",
+						Value: "",
+						Usage: "Comma separated list of columns for table and csv output",
+					},
+					&cli.StringFlag{
+						Name:  "sort-by",
+						Value: "",
+						Usage: "Sort by a field, prefix with a dash for descending order",
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Value: "",
+						Usage: "Filter by expression on the record fields",
+					},
 					&cli.BoolFlag{
-						Name:  "json",
+						Name:  "first",
 						Value: false,
-						Usage: "Print in JSON format",
+						Usage: "Return only first record",
 					},
 					&cli.BoolFlag{
-						Name:  "xml",
+						Name:  "last",
 						Value: false,
-						Usage: "Print in XML format",
+						Usage: "Return only last record",
 					},
 				},
 				Action: func(c *cli.Context) error {
@@ -89,21 +113,16 @@ func Version() *cli.Command {
 						Value: "",
 						Usage: "Version ID or slug to show",
 					},
+					&cli.BoolFlag{
+						Name:  "sha256",
+						Value: false,
+						Usage: "Download the file to calculate the SHA256 hash",
+					},
 					&cli.StringFlag{
 						Name:  "format",
 						Value: tmplVersionShow,
 						Usage: "Custom output format",
 					},
-					&cli.BoolFlag{
-						Name:  "json",
-						Value: false,
-						Usage: "Print in JSON format",
-					},
-					&cli.BoolFlag{
-						Name:  "xml",
-						Value: false,
-						Usage: "Print in XML format",
-					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, VersionShow)
@@ -165,6 +184,11 @@ func Version() *cli.Command {
 						Value: "",
 						Usage: "Provide a file path",
 					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Value: false,
+						Usage: "Upload the file even if the mod already got it",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, VersionUpdate)
@@ -183,12 +207,12 @@ func Version() *cli.Command {
 					&cli.StringFlag{
 						Name:  "slug",
 						Value: "",
-						Usage: "Provide a slug",
+						Usage: "Provide a slug, defaults to the version of the jar",
 					},
 					&cli.StringFlag{
 						Name:  "name",
 						Value: "",
-						Usage: "Provide a name",
+						Usage: "Provide a name, defaults to the version of the jar",
 					},
 					&cli.StringFlag{
 						Name:  "file-url",
@@ -200,6 +224,11 @@ func Version() *cli.Command {
 						Value: "",
 						Usage: "Provide a file path",
 					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Value: false,
+						Usage: "Upload the file even if the mod already got it",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					return Handle(c, VersionCreate)
@@ -230,15 +259,30 @@ func Version() *cli.Command {
 								Value: tmplVersionBuildList,
 								Usage: "Custom output format",
 							},
+							&cli.StringFlag{
+								Name:  "columns",
+								Value: "",
+								Usage: "Comma separated list of columns for table and csv output",
+							},
+							&cli.StringFlag{
+								Name:  "sort-by",
+								Value: "",
+								Usage: "Sort by a field, prefix with a dash for descending order",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Value: "",
+								Usage: "Filter by expression on the record fields",
+							},
 							&cli.BoolFlag{
-								Name:  "json",
+								Name:  "first",
 								Value: false,
-								Usage: "Print in JSON format",
+								Usage: "Return only first record",
 							},
 							&cli.BoolFlag{
-								Name:  "xml",
+								Name:  "last",
 								Value: false,
-								Usage: "Print in XML format",
+								Usage: "Return only last record",
 							},
 						},
 						Action: func(c *cli.Context) error {
@@ -322,60 +366,7 @@ func VersionList(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
-	}
-
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // VersionShow provides the sub-command to show version details.
@@ -389,47 +380,21 @@ func VersionShow(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
+	result := &VersionDetails{
+		Version: record,
 	}
 
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(record, "", "  ")
+	if c.Bool("sha256") {
+		checksum, err := DownloadChecksum(record)
 
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
+		result.SHA256 = checksum.SHA256
 	}
 
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(record, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
-	}
-
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	return tmpl.Execute(os.Stdout, record)
+	return OutputRecord(c, result)
 }
 
 // VersionDelete provides the sub-command to delete a version.
@@ -470,6 +435,11 @@ func VersionUpdate(c *cli.Context, client kleister.ClientAPI) error {
 		changed = true
 	}
 
+	var (
+		checksum *Checksum
+		upload   string
+	)
+
 	if val := c.String("file-url"); c.IsSet("file-url") && val != "" {
 		err := record.DownloadFile(
 			val,
@@ -479,19 +449,38 @@ func VersionUpdate(c *cli.Context, client kleister.ClientAPI) error {
 			return fmt.Errorf("failed to download and encode file")
 		}
 
+		if checksum, err = UploadChecksum(record.File); err != nil {
+			return err
+		}
+
 		changed = true
 	}
 
 	if val := c.String("file-path"); c.IsSet("file-path") && val != "" {
-		err := record.EncodeFile(
-			val,
-		)
+		if checksum, err = FileChecksum(val); err != nil {
+			return err
+		}
 
-		if err != nil {
-			return fmt.Errorf("failed to encode file")
+		upload = val
+	}
+
+	if err := checkDuplicate(client, GetModParam(c), record, checksum, c.Bool("force")); err != nil {
+		return err
+	}
+
+	var (
+		uploader *Uploader
+	)
+
+	if upload != "" {
+		if uploader, err = prepareUpload(c, record, GetModParam(c), upload); err != nil {
+			return err
 		}
 
-		changed = true
+		if uploader == nil {
+			upload = ""
+			changed = true
+		}
 	}
 
 	if changed {
@@ -503,7 +492,23 @@ func VersionUpdate(c *cli.Context, client kleister.ClientAPI) error {
 		if patch != nil {
 			return patch
 		}
+	}
 
+	if upload != "" {
+		err := uploadVersionFile(
+			client,
+			uploader,
+			GetModParam(c),
+			record,
+			upload,
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if changed || upload != "" {
 		fmt.Fprintf(os.Stderr, "Successfully updated\n")
 	} else {
 		fmt.Fprintf(os.Stderr, "Nothing to update...\n")
@@ -542,16 +547,38 @@ func VersionCreate(c *cli.Context, client kleister.ClientAPI) error {
 		}
 	}
 
+	metadata, err := versionMetadata(
+		client,
+		c.String("mod"),
+		c.String("file-path"),
+	)
+
+	if err != nil {
+		return err
+	}
+
 	if val := c.String("name"); c.IsSet("name") && val != "" {
 		record.Name = val
+	} else if metadata != nil && metadata.Version != "" {
+		record.Name = metadata.Version
+	} else if metadata != nil {
+		return fmt.Errorf("failed to detect the version of %s, you must provide a name", c.String("file-path"))
 	} else {
 		return fmt.Errorf("you must provide a name")
 	}
 
 	if val := c.String("slug"); c.IsSet("slug") && val != "" {
 		record.Slug = val
+	} else if metadata != nil && metadata.Version != "" {
+		record.Slug = Slugify(metadata.Version)
 	}
 
+	var (
+		checksum *Checksum
+		upload   string
+		uploader *Uploader
+	)
+
 	if val := c.String("file-url"); c.IsSet("file-url") && val != "" {
 		err := record.DownloadFile(
 			val,
@@ -560,19 +587,31 @@ func VersionCreate(c *cli.Context, client kleister.ClientAPI) error {
 		if err != nil {
 			return fmt.Errorf("failed to download and encode file")
 		}
+
+		if checksum, err = UploadChecksum(record.File); err != nil {
+			return err
+		}
 	}
 
 	if val := c.String("file-path"); c.IsSet("file-path") && val != "" {
-		err := record.EncodeFile(
-			val,
-		)
+		if checksum, err = FileChecksum(val); err != nil {
+			return err
+		}
 
-		if err != nil {
-			return fmt.Errorf("failed to encode file")
+		upload = val
+	}
+
+	if err := checkDuplicate(client, GetModParam(c), record, checksum, c.Bool("force")); err != nil {
+		return err
+	}
+
+	if upload != "" {
+		if uploader, err = prepareUpload(c, record, GetModParam(c), upload); err != nil {
+			return err
 		}
 	}
 
-	_, err := client.VersionPost(
+	created, err := client.VersionPost(
 		GetModParam(c),
 		record,
 	)
@@ -581,77 +620,80 @@ func VersionCreate(c *cli.Context, client kleister.ClientAPI) error {
 		return err
 	}
 
+	if uploader != nil {
+		err := uploadVersionFile(
+			client,
+			uploader,
+			GetModParam(c),
+			created,
+			upload,
+		)
+
+		if err != nil {
+			remove := client.VersionDelete(
+				GetModParam(c),
+				strconv.FormatInt(created.ID, 10),
+			)
+
+			if remove != nil {
+				return fmt.Errorf("%s, failed to remove version %s again", err, created.Slug)
+			}
+
+			return fmt.Errorf("%s, removed version %s again", err, created.Slug)
+		}
+	}
+
 	fmt.Fprintf(os.Stderr, "Successfully created\n")
 	return nil
 }
 
-// VersionBuildList provides the sub-command to list builds of the version.
-func VersionBuildList(c *cli.Context, client kleister.ClientAPI) error {
-	records, err := client.VersionBuildList(
-		kleister.VersionBuildParams{
-			Mod:     GetModParam(c),
-			Version: GetIdentifierParam(c),
-		},
-	)
+// versionMetadata reads the mod metadata if the file is a jar, it warns if the
+// jar belongs to another mod than the version.
+func versionMetadata(client kleister.ClientAPI, mod, path string) (*JarMetadata, error) {
+	if !strings.HasSuffix(strings.ToLower(path), ".jar") {
+		return nil, nil
+	}
+
+	metadata, err := ReadJarMetadata(path)
 
 	if err != nil {
-		return err
+		fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+		return nil, nil
 	}
 
-	if c.IsSet("json") && c.IsSet("xml") {
-		return fmt.Errorf("conflict, you can only use json or xml at once")
+	if metadata.ID == "" {
+		return metadata, nil
 	}
 
-	if c.Bool("xml") {
-		res, err := xml.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
+	related, err := client.ModGet(
+		mod,
+	)
 
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
+	if err != nil {
+		return nil, err
 	}
 
-	if c.Bool("json") {
-		res, err := json.MarshalIndent(records, "", "  ")
-
-		if err != nil {
-			return err
-		}
-
-		fmt.Fprintf(os.Stdout, "%s\n", res)
-		return nil
+	if metadata.Slug() != related.Slug {
+		fmt.Fprintf(os.Stderr, "warning: jar declares mod %s, but the version belongs to %s\n", metadata.ID, related.Slug)
 	}
 
-	if len(records) == 0 {
-		fmt.Fprintf(os.Stderr, "Empty result\n")
-		return nil
-	}
+	return metadata, nil
+}
 
-	tmpl, err := template.New(
-		"_",
-	).Funcs(
-		globalFuncMap,
-	).Funcs(
-		sprigFuncMap,
-	).Parse(
-		fmt.Sprintf("%s\n", c.String("format")),
+// VersionBuildList provides the sub-command to list builds of the version.
+func VersionBuildList(c *cli.Context, client kleister.ClientAPI) error {
+	records, err := client.VersionBuildList(
+		kleister.VersionBuildParams{
+			Mod:     GetModParam(c),
+			Version: GetIdentifierParam(c),
+		},
 	)
 
 	if err != nil {
 		return err
 	}
 
-	for _, record := range records {
-		err := tmpl.Execute(os.Stdout, record)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return OutputList(c, records)
 }
 
 // VersionBuildAppend provides the sub-command to append a build to the version.
@@ -1,16 +1,21 @@
 module github.com/kleister/kleister-cli
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/Knetic/govaluate v3.0.0+incompatible
 	github.com/Masterminds/goutils v1.1.0 // indirect
 	github.com/Masterminds/semver v1.4.2 // indirect
 	github.com/Masterminds/sprig v2.18.0+incompatible
 	github.com/google/uuid v1.1.1 // indirect
+	github.com/hashicorp/go-version v1.2.0
 	github.com/huandu/xstrings v1.2.0 // indirect
 	github.com/imdario/mergo v0.3.7 // indirect
+	github.com/jackspirou/syscerts v0.0.0-20160531025014-b68f5469dff1
 	github.com/joho/godotenv v1.3.0
 	github.com/kleister/kleister-go v0.0.0-20190507072323-f243df717ba2
 	github.com/mitchellh/gox v1.0.1 // indirect
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
 	gopkg.in/guregu/null.v3 v3.4.0
 	gopkg.in/urfave/cli.v2 v2.0.0-20180128182452-d3ae77c26ac8
+	gopkg.in/yaml.v2 v2.2.2
 )